@@ -5,5 +5,7 @@ go 1.13
 require (
 	//github.com/ethereum/go-ethereum v1.9.11
 	github.com/ethereum/go-ethereum v1.10.5
+	github.com/google/uuid v1.1.5
 	github.com/sirupsen/logrus v1.4.2
+	github.com/tyler-smith/go-bip39 v1.0.1-0.20181017060643-dbb3b84ba2ef
 )
@@ -15,11 +15,15 @@ import (
  * NOTE: for constructor : set methodName to empty string
 **/
 func Pack(abiStr string, methodName string, args string) ([]byte, error) {
-	abiObj, err := abi.JSON(strings.NewReader(abiStr))
+	abiObj, _, err := parseABI(abiStr)
 	if err != nil {
 		return nil, fmt.Errorf("abi.JSON error: %v", err)
 	}
+	return packABI(abiObj, methodName, args)
+}
 
+// packABI is Pack with a parsed abi
+func packABI(abiObj abi.ABI, methodName string, args string) ([]byte, error) {
 	var resultArgs []interface{}
 	var allArgs []string
 	if len(args) > 0 {
@@ -32,33 +36,37 @@ func Pack(abiStr string, methodName string, args string) ([]byte, error) {
 		if len(arg) == 0 {
 			continue
 		}
+		index := len(resultArgs)
 		// arg format: type:value
 		splitArg := strings.Split(arg, ":")
 		if len(splitArg) != 2 {
-			return nil, fmt.Errorf("args format error")
+			return nil, &ArgError{Method: methodName, Index: index, Err: fmt.Errorf("args format error")}
 		}
 		typ := splitArg[0]
 		val := splitArg[1]
+		argErr := func(err error) error {
+			return &ArgError{Method: methodName, Index: index, Type: typ, Err: err}
+		}
 
 		switch typ {
 		case "uint256":
 			v, err := DecodeUint256String(val)
 			if err != nil {
-				return nil, err
+				return nil, argErr(err)
 			}
 			resultArgs = append(resultArgs, v)
 
 		case "bytes":
 			hexVal, err := DecodeHexString(val)
 			if err != nil {
-				return nil, fmt.Errorf("bytes value format error")
+				return nil, argErr(fmt.Errorf("bytes value format error"))
 			}
 			resultArgs = append(resultArgs, []byte(hexVal))
 
 		case "bytes32":
 			v, err := DecodeBytes32String(val)
 			if err != nil {
-				return nil, err
+				return nil, argErr(err)
 			}
 			resultArgs = append(resultArgs, v)
 
@@ -69,58 +77,121 @@ func Pack(abiStr string, methodName string, args string) ([]byte, error) {
 		case "uint256[]":
 			v, err := DecodeUint256StringArray(val)
 			if err != nil {
-				return nil, err
+				return nil, argErr(err)
 			}
 			resultArgs = append(resultArgs, v)
 
 		case "bytes32[]":
 			arr, err := DecodeBytes32StringArray(val)
 			if err != nil {
-				return nil, err
+				return nil, argErr(err)
 			}
 			resultArgs = append(resultArgs, arr)
 
 		case "bytes[]":
 			arr, err := DecodeBytesStringArray(val)
 			if err != nil {
-				return nil, err
+				return nil, argErr(err)
 			}
 			resultArgs = append(resultArgs, arr)
 
 		case "address":
 			hexVal, err := DecodeHexString(val)
 			if err != nil {
-				return nil, fmt.Errorf("address format error")
+				return nil, argErr(fmt.Errorf("address format error"))
 			}
 			resultArgs = append(resultArgs, common.BytesToAddress(hexVal))
 
 		case "address[]":
 			hexArray, err := DecodeAddressStringArray(val)
 			if err != nil {
-				return nil, fmt.Errorf("address[] format error")
+				return nil, argErr(fmt.Errorf("address[] format error"))
 			}
 			resultArgs = append(resultArgs, hexArray)
 
 		default:
 			// TODO
 			// uint256[2]...
-			return nil, fmt.Errorf("Not support type: %v", typ)
+			return nil, argErr(fmt.Errorf("Not support type: %v", typ))
 		}
 	}
 	result, err := abiObj.Pack(methodName, resultArgs...)
 	if err != nil {
+		if argErr := packArgError(abiObj, methodName, resultArgs); argErr != nil {
+			return nil, argErr
+		}
 		return nil, fmt.Errorf("abi.JSON() error: %v", err)
 	}
 	return result, nil
 
 }
 
+// packArgError finds the argument failed to pack by packing arguments one by one, nil when not found
+func packArgError(abiObj abi.ABI, methodName string, args []interface{}) error {
+	inputs := abiObj.Constructor.Inputs
+	if methodName != "" {
+		method, ok := abiObj.Methods[methodName]
+		if !ok {
+			return nil
+		}
+		inputs = method.Inputs
+	}
+	if len(args) != len(inputs) {
+		return &ArgError{Method: methodName, Index: len(args), Err: fmt.Errorf("%v arguments are provided, want %v", len(args), len(inputs))}
+	}
+	for i, input := range inputs {
+		if _, e := (abi.Arguments{input}).Pack(args[i]); e != nil {
+			return &ArgError{Method: methodName, Index: i, Type: input.Type.String(), Err: e}
+		}
+	}
+	return nil
+}
+
 // Unpack decodes output
 func Unpack(abiStr string, methodName string, returnData []byte) ([]interface{}, error) {
-	abiObj, err := abi.JSON(strings.NewReader(abiStr))
+	abiObj, _, err := parseABI(abiStr)
 	if err != nil {
 		return nil, err
 	}
+	return unpackABI(abiObj, methodName, returnData)
+}
 
-	return abiObj.Unpack(methodName, returnData)
+// unpackABI is Unpack with a parsed abi
+func unpackABI(abiObj abi.ABI, methodName string, returnData []byte) ([]interface{}, error) {
+	// e.g. a call to an address without code
+	if method, ok := abiObj.Methods[methodName]; ok && len(returnData) == 0 && len(method.Outputs) > 0 {
+		return nil, &ReturnTypeError{Method: methodName, Want: outputsType(method.Outputs)}
+	}
+	values, err := abiObj.Unpack(methodName, returnData)
+	if err != nil {
+		return nil, unpackArgError(abiObj, methodName, returnData, err)
+	}
+	return values, nil
+}
+
+// outputsType is the abi type of outputs, e.g. uint256 or (uint256,string)
+func outputsType(outputs abi.Arguments) string {
+	if len(outputs) == 1 {
+		return outputs[0].Type.String()
+	}
+	types := make([]string, len(outputs))
+	for i, output := range outputs {
+		types[i] = output.Type.String()
+	}
+	return "(" + strings.Join(types, ",") + ")"
+}
+
+// unpackArgError finds the output failed to unpack, it's the last one of the shortest failed prefix of outputs,
+// since heads of outputs are in order. err is returned when not found
+func unpackArgError(abiObj abi.ABI, methodName string, returnData []byte, err error) error {
+	method, ok := abiObj.Methods[methodName]
+	if !ok {
+		return err
+	}
+	for i, output := range method.Outputs {
+		if _, e := method.Outputs[:i+1].UnpackValues(returnData); e != nil {
+			return &ArgError{Method: methodName, Index: i, Type: output.Type.String(), Err: e}
+		}
+	}
+	return err
 }
@@ -0,0 +1,166 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// AccessListResult is the access list generated by eth_createAccessList
+// and the gas of the call with and without it, both estimated by eth_estimateGas
+type AccessListResult struct {
+	AccessList     types.AccessList
+	GasWithList    uint64
+	GasWithoutList uint64
+}
+
+// GasSaved returns gas saved by the access list, negative when the list costs more gas
+func (r *AccessListResult) GasSaved() int64 {
+	return int64(r.GasWithoutList) - int64(r.GasWithList)
+}
+
+// CreateAccessList calls eth_createAccessList for the call from fromAddr to toAddr,
+// and estimates gas of the same call with and without the access list
+// set toAddr to empty string for contract creation
+func (tm *TransactionManager) CreateAccessList(fromAddr string, toAddr string, value *big.Int, data []byte) (*AccessListResult, error) {
+	return tm.CreateAccessListContext(context.Background(), fromAddr, toAddr, value, data)
+}
+
+// CreateAccessListContext is like CreateAccessList but with ctx for cancellation and deadline
+func (tm *TransactionManager) CreateAccessListContext(ctx context.Context, fromAddr string, toAddr string, value *big.Int, data []byte) (*AccessListResult, error) {
+	msg := ethereum.CallMsg{
+		From:  common.HexToAddress(fromAddr),
+		Value: value,
+		Data:  data,
+	}
+	if toAddr != "" {
+		to := common.HexToAddress(toAddr)
+		msg.To = &to
+	}
+
+	accessList, _, vmErr, err := tm.gethClient.CreateAccessList(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAccessList() error: %s", err.Error())
+	}
+	if vmErr != "" {
+		return nil, fmt.Errorf("CreateAccessList() execution error: %s", vmErr)
+	}
+	result := &AccessListResult{}
+	if accessList != nil {
+		result.AccessList = *accessList
+	}
+
+	// gas used by eth_createAccessList is not comparable with an estimate, so both are estimated
+	result.GasWithoutList, err = tm.estimateCallGas(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("EstimateGas() error: %s", err.Error())
+	}
+	msg.AccessList = result.AccessList
+	result.GasWithList, err = tm.estimateCallGas(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("EstimateGas() error: %s", err.Error())
+	}
+	return result, nil
+}
+
+// estimateCallGas estimates gas of msg, unlike ethclient it sends the access list of msg
+func (tm *TransactionManager) estimateCallGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	var gas hexutil.Uint64
+	if err := tm.rpcClient.CallContext(ctx, &gas, "eth_estimateGas", toCallArg(msg)); err != nil {
+		return 0, err
+	}
+	return uint64(gas), nil
+}
+
+// toCallArg is the call object of msg in json rpc
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if msg.AccessList != nil {
+		arg["accessList"] = msg.AccessList
+	}
+	return arg
+}
+
+// SendTxWithAccessList sends an async tx carrying accessList, and return tx's hash
+// the tx is EIP-2930 (type 1) transaction, or EIP-1559 (type 2) transaction when dynamic fee is enabled
+// other arguments are the same as SendTx
+func (tm *TransactionManager) SendTxWithAccessList(signer Signer, toAddr string, value *big.Int, data []byte, accessList types.AccessList, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, error) {
+	return tm.SendTxWithAccessListContext(context.Background(), signer, toAddr, value, data, accessList, gasPrice, nonce, gasLimit)
+}
+
+// SendTxWithAccessListContext is like SendTxWithAccessList but with ctx for cancellation and deadline
+func (tm *TransactionManager) SendTxWithAccessListContext(ctx context.Context, signer Signer, toAddr string, value *big.Int, data []byte, accessList types.AccessList, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, error) {
+	if accessList == nil {
+		accessList = types.AccessList{}
+	}
+	return tm.sendTx(ctx, signer, toAddr, value, data, accessList, "", gasPrice, nonce, gasLimit)
+}
+
+// WriteContractWithAccessList sends an async write contract with access list generated by node,
+// return hash, access list result, error
+// the access list is attached only when it saves gas, otherwise the tx is sent as WriteContract does
+func (tm *TransactionManager) WriteContractWithAccessList(signer Signer, contractAddress string, v *big.Int, abi string, methodName, args string, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, *AccessListResult, error) {
+	return tm.WriteContractWithAccessListContext(context.Background(), signer, contractAddress, v, abi, methodName, args, gasPrice, nonce, gasLimit)
+}
+
+// WriteContractWithAccessListContext is like WriteContractWithAccessList but with ctx for cancellation and deadline
+func (tm *TransactionManager) WriteContractWithAccessListContext(ctx context.Context, signer Signer, contractAddress string, v *big.Int, abi string, methodName, args string, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, *AccessListResult, error) {
+	payload, err := Pack(abi, methodName, args)
+	if err != nil {
+		return "", nil, err
+	}
+	result, err := tm.CreateAccessListContext(ctx, signer.Address().String(), contractAddress, v, payload)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var accessList types.AccessList
+	if result.GasSaved() > 0 {
+		accessList = result.AccessList
+	}
+	hash, err := tm.sendTx(ctx, signer, contractAddress, v, payload, accessList, abi, gasPrice, nonce, gasLimit)
+	if err != nil {
+		return "", nil, err
+	}
+	return hash, result, nil
+}
+
+// WriteContractWithAccessListSync sends an sync write contract with access list generated by node,
+// return hash, gas used, access list result, error
+func (tm *TransactionManager) WriteContractWithAccessListSync(signer Signer, contractAddress string, v *big.Int, abi string, methodName, args string, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, uint64, *AccessListResult, error) {
+	return tm.WriteContractWithAccessListSyncContext(context.Background(), signer, contractAddress, v, abi, methodName, args, gasPrice, nonce, gasLimit)
+}
+
+// WriteContractWithAccessListSyncContext is like WriteContractWithAccessListSync but with ctx for cancellation and deadline
+func (tm *TransactionManager) WriteContractWithAccessListSyncContext(ctx context.Context, signer Signer, contractAddress string, v *big.Int, abi string, methodName, args string, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, uint64, *AccessListResult, error) {
+	hash, result, err := tm.WriteContractWithAccessListContext(ctx, signer, contractAddress, v, abi, methodName, args, gasPrice, nonce, gasLimit)
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	receipt, err := tm.waitSuccess(ctx, hash, abi)
+	if receipt == nil {
+		return "", 0, nil, err
+	}
+	return hash, receipt.GasUsed, result, err
+}
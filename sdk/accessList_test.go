@@ -0,0 +1,64 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCreateAccessList(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.close()
+	token := testAddress(0xaa)
+	list := types.AccessList{{Address: common.HexToAddress(token), StorageKeys: []common.Hash{{1}}}}
+	withList := uint64(28000)
+	backend.set(func(b *testBackend) {
+		b.accessList = list
+		b.estimate = func(args testCallArgs) (uint64, error) {
+			if args.AccessList != nil && len(*args.AccessList) > 0 {
+				return withList, nil
+			}
+			return 30000, nil
+		}
+	})
+	tm, closeFn := backend.newTM(t)
+	defer closeFn()
+	signer := hexSigner(t, testSignerKey)
+
+	result, err := tm.CreateAccessList(signer.Address().String(), token, nil, []byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.AccessList) != 1 || result.GasWithList != 28000 || result.GasWithoutList != 30000 || result.GasSaved() != 2000 {
+		t.Fatalf("result: %+v", result)
+	}
+
+	// the list saves gas, tx is EIP-2930
+	args := "address:" + testAddress(1) + ";uint256:1"
+	if _, _, err := tm.WriteContractWithAccessList(signer, token, nil, ERC20_ABI, MethodTransfer, args, nil, 1, 50000); err != nil {
+		t.Fatal(err)
+	}
+	if tx := backend.lastSent(t); tx.Type() != types.AccessListTxType || len(tx.AccessList()) != 1 {
+		t.Fatalf("type %v access list %v", tx.Type(), tx.AccessList())
+	}
+
+	// the list costs more gas, tx is sent without it
+	backend.set(func(b *testBackend) { withList = 31000 })
+	_, result, err = tm.WriteContractWithAccessList(signer, token, nil, ERC20_ABI, MethodTransfer, args, nil, 2, 50000)
+	if err != nil || result.GasSaved() != -1000 {
+		t.Fatalf("result: %+v %v", result, err)
+	}
+	if tx := backend.lastSent(t); tx.Type() != types.LegacyTxType {
+		t.Fatalf("type %v", tx.Type())
+	}
+
+	// EIP-1559 tx carries the list too
+	tm.EnableDynamicFee()
+	if _, err := tm.SendTxWithAccessList(signer, token, nil, []byte{1}, list, nil, 3, 50000); err != nil {
+		t.Fatal(err)
+	}
+	if tx := backend.lastSent(t); tx.Type() != types.DynamicFeeTxType || len(tx.AccessList()) != 1 {
+		t.Fatalf("type %v access list %v", tx.Type(), tx.AccessList())
+	}
+}
@@ -0,0 +1,401 @@
+package sdk
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testBackend is a node of chain 5 serving eth_ methods over HTTP for tests,
+// it's served over HTTP so receiptWaiter polls blocks instead of subscribing new heads
+// sent transactions are kept in pool until they are mined
+type testBackend struct {
+	http   *httptest.Server
+	server *rpc.Server
+
+	mu         sync.Mutex
+	requests   int            // HTTP requests, a JSON-RPC batch is one request
+	calls      map[string]int // calls of each method
+	block      uint64
+	syncing    bool
+	baseFee    *big.Int // base fee of blocks, nil before London
+	gasPrice   *big.Int
+	tip        *big.Int
+	balance    func(address common.Address, block string) *big.Int   // 1 ether when it's nil
+	nonce      func(address common.Address, block string) uint64     // 0 when it's nil
+	call       func(args testCallArgs, block string) ([]byte, error) // eth_call, empty output when it's nil
+	code       func(address common.Address) []byte                   // eth_getCode, no code when it's nil
+	estimate   func(args testCallArgs) (uint64, error)               // eth_estimateGas, 21000 when it's nil
+	accessList types.AccessList                                      // result of eth_createAccessList
+	sendErr    func(tx *types.Transaction) error                     // refuses tx when it returns error
+	lost       func(tx *types.Transaction) bool                      // accepts tx but answers gateway timeout when it returns true
+	status     func(tx *types.Transaction) uint64                    // status of receipts of mine, successful when it's nil
+	sent       []*types.Transaction
+	pool       map[common.Hash]*types.Transaction
+	pooled     map[common.Address]int // transactions of each sender in pool
+	maxPooled  map[common.Address]int // most transactions of each sender in pool at once
+	receipts   map[common.Hash]*types.Receipt
+}
+
+// newTestBackend starts a testBackend at block 1 with base fee 1 gwei, gas price 2 gwei and tip 1 gwei
+func newTestBackend(t *testing.T) *testBackend {
+	b := &testBackend{
+		calls:     make(map[string]int),
+		block:     1,
+		baseFee:   big.NewInt(1e9),
+		gasPrice:  big.NewInt(2e9),
+		tip:       big.NewInt(1e9),
+		pool:      make(map[common.Hash]*types.Transaction),
+		pooled:    make(map[common.Address]int),
+		maxPooled: make(map[common.Address]int),
+		receipts:  make(map[common.Hash]*types.Receipt),
+	}
+	b.server = rpc.NewServer()
+	if err := b.server.RegisterName("eth", b); err != nil {
+		t.Fatal(err)
+	}
+	b.http = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		b.requests++
+		b.mu.Unlock()
+		lost := new(bool)
+		rec := httptest.NewRecorder()
+		b.server.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), lostResponse{}, lost)))
+		if *lost {
+			http.Error(w, "gateway timeout", http.StatusGatewayTimeout)
+			return
+		}
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	}))
+	return b
+}
+
+// lostResponse is the context key of the flag making the HTTP response of a request lost
+type lostResponse struct{}
+
+func (b *testBackend) close() {
+	b.http.Close()
+	b.server.Stop()
+}
+
+// newTM makes a TransactionManager over b, it sends legacy transactions at gas price 1 gwei by default
+func (b *testBackend) newTM(t *testing.T) (*TransactionManager, func()) {
+	client, err := rpc.DialHTTP(b.http.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm := &TransactionManager{
+		rpcClient:        client,
+		Client:           ethclient.NewClient(client),
+		gethClient:       gethclient.New(client),
+		chainID:          "5",
+		eip155:           true,
+		gasLimit:         21000,
+		timeout:          5,
+		gasPriceStrategy: NewFixedGasPrice(big.NewInt(1e9), nil),
+	}
+	tm.waiter = newReceiptWaiter(tm.Client, 5*time.Millisecond)
+	return tm, tm.Close
+}
+
+// testAddress returns the address ending with byte b
+func testAddress(b byte) string {
+	return common.BytesToAddress([]byte{b}).String()
+}
+
+// set changes b with its lock held
+func (b *testBackend) set(f func(b *testBackend)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f(b)
+}
+
+// count returns calls of method, e.g. eth_sendRawTransaction
+func (b *testBackend) count(method string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls[method]
+}
+
+// requestCount returns HTTP requests served by b
+func (b *testBackend) requestCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.requests
+}
+
+// lastSent returns the last transaction accepted by b
+func (b *testBackend) lastSent(t *testing.T) *types.Transaction {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.sent) == 0 {
+		t.Fatal("no transaction is sent")
+	}
+	return b.sent[len(b.sent)-1]
+}
+
+// mine includes transactions of hashes in pool in a new block, all transactions of pool when hashes is empty
+func (b *testBackend) mine(hashes ...common.Hash) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.block++
+	if len(hashes) == 0 {
+		for hash := range b.pool {
+			hashes = append(hashes, hash)
+		}
+	}
+	for _, hash := range hashes {
+		tx, ok := b.pool[hash]
+		if !ok {
+			continue
+		}
+		status := types.ReceiptStatusSuccessful
+		if b.status != nil {
+			status = b.status(tx)
+		}
+		b.receipts[hash] = &types.Receipt{
+			TxHash:      hash,
+			Status:      status,
+			BlockNumber: new(big.Int).SetUint64(b.block),
+			BlockHash:   common.Hash{byte(b.block)},
+			GasUsed:     tx.Gas(),
+			Logs:        []*types.Log{},
+		}
+		b.removePool(hash)
+	}
+}
+
+// forget drops hash from pool as if node restarted
+func (b *testBackend) forget(hash common.Hash) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removePool(hash)
+}
+
+func (b *testBackend) addPool(tx *types.Transaction) {
+	if _, ok := b.pool[tx.Hash()]; ok {
+		return
+	}
+	b.pool[tx.Hash()] = tx
+	from, _ := recoverSender(tx)
+	b.pooled[from]++
+	if b.pooled[from] > b.maxPooled[from] {
+		b.maxPooled[from] = b.pooled[from]
+	}
+}
+
+func (b *testBackend) removePool(hash common.Hash) {
+	tx, ok := b.pool[hash]
+	if !ok {
+		return
+	}
+	delete(b.pool, hash)
+	from, _ := recoverSender(tx)
+	b.pooled[from]--
+}
+
+// sentNonces returns nonces of transactions of from accepted by b, in the order they are sent
+func (b *testBackend) sentNonces(from common.Address) []uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var nonces []uint64
+	for _, tx := range b.sent {
+		if sender, _ := recoverSender(tx); sender == from {
+			nonces = append(nonces, tx.Nonce())
+		}
+	}
+	return nonces
+}
+
+func (b *testBackend) ChainId() *hexutil.Big {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_chainId"]++
+	return (*hexutil.Big)(big.NewInt(5))
+}
+
+func (b *testBackend) BlockNumber() hexutil.Uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_blockNumber"]++
+	return hexutil.Uint64(b.block)
+}
+
+// Syncing returns false, or progress of sync when b is syncing
+func (b *testBackend) Syncing() interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_syncing"]++
+	if !b.syncing {
+		return false
+	}
+	return map[string]hexutil.Uint64{"currentBlock": hexutil.Uint64(b.block), "highestBlock": hexutil.Uint64(b.block + 100)}
+}
+
+func (b *testBackend) GetBlockByNumber(number string, full bool) *types.Header {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_getBlockByNumber"]++
+	return &types.Header{
+		Number:     new(big.Int).SetUint64(b.block),
+		Difficulty: big.NewInt(1),
+		GasLimit:   30e6,
+		BaseFee:    b.baseFee,
+	}
+}
+
+func (b *testBackend) GasPrice() *hexutil.Big {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_gasPrice"]++
+	return (*hexutil.Big)(b.gasPrice)
+}
+
+func (b *testBackend) MaxPriorityFeePerGas() *hexutil.Big {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_maxPriorityFeePerGas"]++
+	return (*hexutil.Big)(b.tip)
+}
+
+func (b *testBackend) GetBalance(address common.Address, block string) *hexutil.Big {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_getBalance"]++
+	if b.balance == nil {
+		return (*hexutil.Big)(big.NewInt(1e18))
+	}
+	return (*hexutil.Big)(b.balance(address, block))
+}
+
+func (b *testBackend) GetTransactionCount(address common.Address, block string) hexutil.Uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_getTransactionCount"]++
+	if b.nonce == nil {
+		return 0
+	}
+	return hexutil.Uint64(b.nonce(address, block))
+}
+
+func (b *testBackend) SendRawTransaction(ctx context.Context, raw hexutil.Bytes) (common.Hash, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_sendRawTransaction"]++
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	if b.sendErr != nil {
+		if err := b.sendErr(tx); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	b.sent = append(b.sent, tx)
+	b.addPool(tx)
+	if b.lost != nil && b.lost(tx) {
+		if lost, ok := ctx.Value(lostResponse{}).(*bool); ok {
+			*lost = true
+		}
+	}
+	return tx.Hash(), nil
+}
+
+// SendTransaction is eth_sendTransaction signed by an account of node, it's only counted
+func (b *testBackend) SendTransaction(args testCallArgs) common.Hash {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_sendTransaction"]++
+	return common.Hash{1}
+}
+
+func (b *testBackend) GetTransactionByHash(hash common.Hash) *types.Transaction {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_getTransactionByHash"]++
+	return b.pool[hash]
+}
+
+func (b *testBackend) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_getTransactionReceipt"]++
+	return b.receipts[hash]
+}
+
+// testRevert is the rpc error of a reverted eth_call or eth_estimateGas carrying revert data
+type testRevert struct{ data []byte }
+
+func (e testRevert) Error() string          { return "execution reverted" }
+func (e testRevert) ErrorCode() int         { return 3 }
+func (e testRevert) ErrorData() interface{} { return hexutil.Encode(e.data) }
+
+// testCallArgs is the call object of eth_call, eth_estimateGas and eth_createAccessList
+type testCallArgs struct {
+	From       common.Address    `json:"from"`
+	To         *common.Address   `json:"to"`
+	Gas        *hexutil.Uint64   `json:"gas"`
+	GasPrice   *hexutil.Big      `json:"gasPrice"`
+	Value      *hexutil.Big      `json:"value"`
+	Data       hexutil.Bytes     `json:"data"`
+	AccessList *types.AccessList `json:"accessList"`
+}
+
+func (b *testBackend) Call(args testCallArgs, block string) (hexutil.Bytes, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_call"]++
+	if b.call == nil {
+		return hexutil.Bytes{}, nil
+	}
+	return b.call(args, block)
+}
+
+func (b *testBackend) GetCode(address common.Address, block string) hexutil.Bytes {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_getCode"]++
+	if b.code == nil {
+		return hexutil.Bytes{}
+	}
+	return b.code(address)
+}
+
+func (b *testBackend) EstimateGas(args testCallArgs) (hexutil.Uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_estimateGas"]++
+	if b.estimate == nil {
+		return 21000, nil
+	}
+	gas, err := b.estimate(args)
+	return hexutil.Uint64(gas), err
+}
+
+type testAccessListResult struct {
+	AccessList types.AccessList `json:"accessList"`
+	GasUsed    hexutil.Uint64   `json:"gasUsed"`
+}
+
+func (b *testBackend) CreateAccessList(args testCallArgs, block *string) testAccessListResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls["eth_createAccessList"]++
+	// gas used is not an estimate, it must not be compared with one
+	return testAccessListResult{AccessList: b.accessList, GasUsed: 1}
+}
@@ -0,0 +1,305 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// requests in a JSON-RPC batch, many providers limit it to 100 or so
+	defaultBatchSize = 100
+)
+
+// BalanceResult is the balance of Address got by BatchGetBalance or BatchBalanceOf20
+type BalanceResult struct {
+	Address string
+	Balance *big.Int
+	Err     error
+}
+
+// NonceResult is the nonce of Address got by BatchNonce or BatchPendingNonce
+type NonceResult struct {
+	Address string
+	Nonce   uint64
+	Err     error
+}
+
+// ReceiptResult is the receipt of Hash got by BatchReceipt, Err is ethereum.NotFound when tx is not mined
+type ReceiptResult struct {
+	Hash    string
+	Receipt *types.Receipt
+	Err     error
+}
+
+// ContractCall is a call of BatchReadContract, Args is in format of Pack
+type ContractCall struct {
+	Contract string
+	ABI      string
+	Method   string
+	Args     string
+}
+
+// CallResult is the output of a ContractCall, Values is unpacked output
+type CallResult struct {
+	Output []byte
+	Values []interface{}
+	Err    error
+}
+
+// SetBatchSize sets how many requests the Batch* helpers send in a JSON-RPC batch, 0 for 100
+func (tm *TransactionManager) SetBatchSize(size int) {
+	tm.batchSize = size
+}
+
+// batchCall sends elems in batches of tm.batchSize, error of a batch is set to all elements of it,
+// a failed batch is retried with tm.retry as it's only reads
+func (tm *TransactionManager) batchCall(ctx context.Context, elems []rpc.BatchElem) {
+	size := tm.batchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	for start := 0; start < len(elems); start += size {
+		end := start + size
+		if end > len(elems) {
+			end = len(elems)
+		}
+		chunk := elems[start:end]
+		err := tm.retry.Do(ctx, func() error {
+			return tm.rpcClient.BatchCallContext(ctx, chunk)
+		})
+		if err != nil {
+			for i := range chunk {
+				chunk[i].Error = err
+			}
+		}
+	}
+}
+
+// blockNumberArg is the block parameter of number, nil for latest block
+func blockNumberArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(number)
+}
+
+// BatchGetBalance gets balances of addresses at latest block with JSON-RPC batches,
+// results are in the order of addresses, each with its own error
+func (tm *TransactionManager) BatchGetBalance(addresses []string) []BalanceResult {
+	return tm.BatchGetBalanceContext(context.Background(), addresses)
+}
+
+// BatchGetBalanceContext is like BatchGetBalance but with ctx for cancellation and deadline
+func (tm *TransactionManager) BatchGetBalanceContext(ctx context.Context, addresses []string) []BalanceResult {
+	balances := make([]hexutil.Big, len(addresses))
+	elems := make([]rpc.BatchElem, len(addresses))
+	for i, address := range addresses {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getBalance",
+			Args:   []interface{}{common.HexToAddress(address), "latest"},
+			Result: &balances[i],
+		}
+	}
+	tm.batchCall(ctx, elems)
+
+	results := make([]BalanceResult, len(addresses))
+	for i, address := range addresses {
+		results[i] = BalanceResult{Address: address, Err: elems[i].Error}
+		if elems[i].Error == nil {
+			results[i].Balance = balances[i].ToInt()
+		}
+	}
+	return results
+}
+
+// BatchNonce gets nonces of addresses at latest block with JSON-RPC batches
+func (tm *TransactionManager) BatchNonce(addresses []string) []NonceResult {
+	return tm.BatchNonceContext(context.Background(), addresses)
+}
+
+// BatchNonceContext is like BatchNonce but with ctx for cancellation and deadline
+func (tm *TransactionManager) BatchNonceContext(ctx context.Context, addresses []string) []NonceResult {
+	return tm.batchNonce(ctx, addresses, "latest")
+}
+
+// BatchPendingNonce gets pending nonces of addresses, which count transactions in tx pool, with JSON-RPC batches
+func (tm *TransactionManager) BatchPendingNonce(addresses []string) []NonceResult {
+	return tm.BatchPendingNonceContext(context.Background(), addresses)
+}
+
+// BatchPendingNonceContext is like BatchPendingNonce but with ctx for cancellation and deadline
+func (tm *TransactionManager) BatchPendingNonceContext(ctx context.Context, addresses []string) []NonceResult {
+	return tm.batchNonce(ctx, addresses, "pending")
+}
+
+func (tm *TransactionManager) batchNonce(ctx context.Context, addresses []string, block string) []NonceResult {
+	nonces := make([]hexutil.Uint64, len(addresses))
+	elems := make([]rpc.BatchElem, len(addresses))
+	for i, address := range addresses {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getTransactionCount",
+			Args:   []interface{}{common.HexToAddress(address), block},
+			Result: &nonces[i],
+		}
+	}
+	tm.batchCall(ctx, elems)
+
+	results := make([]NonceResult, len(addresses))
+	for i, address := range addresses {
+		results[i] = NonceResult{Address: address, Nonce: uint64(nonces[i]), Err: elems[i].Error}
+	}
+	return results
+}
+
+// BatchReceipt gets receipts of hashes with JSON-RPC batches, Err of a tx not mined is ethereum.NotFound
+func (tm *TransactionManager) BatchReceipt(hashes []string) []ReceiptResult {
+	return tm.BatchReceiptContext(context.Background(), hashes)
+}
+
+// BatchReceiptContext is like BatchReceipt but with ctx for cancellation and deadline
+func (tm *TransactionManager) BatchReceiptContext(ctx context.Context, hashes []string) []ReceiptResult {
+	receipts := make([]*types.Receipt, len(hashes))
+	elems := make([]rpc.BatchElem, len(hashes))
+	for i, hash := range hashes {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{common.HexToHash(hash)},
+			Result: &receipts[i],
+		}
+	}
+	tm.batchCall(ctx, elems)
+
+	results := make([]ReceiptResult, len(hashes))
+	for i, hash := range hashes {
+		results[i] = ReceiptResult{Hash: hash, Receipt: receipts[i], Err: elems[i].Error}
+		if results[i].Err == nil && receipts[i] == nil {
+			results[i].Err = ethereum.NotFound
+		}
+	}
+	return results
+}
+
+// BatchReadContract calls readonly functions of contracts at blockNumber with JSON-RPC batches,
+// set blockNumber to nil for latest block. Output of each call is unpacked to Values with its ABI
+func (tm *TransactionManager) BatchReadContract(calls []ContractCall, blockNumber *big.Int) []CallResult {
+	return tm.BatchReadContractContext(context.Background(), calls, blockNumber)
+}
+
+// BatchReadContractContext is like BatchReadContract but with ctx for cancellation and deadline
+func (tm *TransactionManager) BatchReadContractContext(ctx context.Context, calls []ContractCall, blockNumber *big.Int) []CallResult {
+	results := make([]CallResult, len(calls))
+	outputs := make([]hexutil.Bytes, len(calls))
+	var (
+		elems   []rpc.BatchElem
+		indexes []int // index of call of elems
+	)
+	abis := make(abiCache)
+	for i, call := range calls {
+		abiObj, err := abis.parse(call.ABI)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		payload, err := packABI(abiObj, call.Method, call.Args)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		to := common.HexToAddress(call.Contract)
+		elems = append(elems, rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{
+				map[string]interface{}{"to": &to, "data": hexutil.Bytes(payload)},
+				blockNumberArg(blockNumber),
+			},
+			Result: &outputs[i],
+		})
+		indexes = append(indexes, i)
+	}
+	tm.batchCall(ctx, elems)
+
+	for j, i := range indexes {
+		if err := elems[j].Error; err != nil {
+			if revertErr := newRevertError(err, calls[i].ABI); revertErr != nil {
+				err = revertErr
+			}
+			results[i].Err = err
+			continue
+		}
+		results[i].Output = outputs[i]
+		abiObj, _ := abis.parse(calls[i].ABI)
+		results[i].Values, results[i].Err = unpackABI(abiObj, calls[i].Method, outputs[i])
+	}
+	return results
+}
+
+// abiCache parses each distinct abi once, as calls often share one
+type abiCache map[string]parsedABI
+
+type parsedABI struct {
+	abi abi.ABI
+	err error
+}
+
+// parse returns abiStr parsed, error is in the format of Pack
+func (c abiCache) parse(abiStr string) (abi.ABI, error) {
+	parsed, ok := c[abiStr]
+	if !ok {
+		parsed.abi, _, parsed.err = parseABI(abiStr)
+		if parsed.err != nil {
+			parsed.err = fmt.Errorf("abi.JSON error: %v", parsed.err)
+		}
+		c[abiStr] = parsed
+	}
+	return parsed.abi, parsed.err
+}
+
+// BatchBalanceOf20 gets ERC20 balances of owners with JSON-RPC batches
+func (tm *TransactionManager) BatchBalanceOf20(contractAddress string, owners []string) []BalanceResult {
+	return tm.BatchBalanceOf20Context(context.Background(), contractAddress, owners)
+}
+
+// BatchBalanceOf20Context is like BatchBalanceOf20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) BatchBalanceOf20Context(ctx context.Context, contractAddress string, owners []string) []BalanceResult {
+	calls := make([]ContractCall, len(owners))
+	for i, owner := range owners {
+		calls[i] = ContractCall{
+			Contract: contractAddress,
+			ABI:      ERC20_ABI,
+			Method:   MethodBalanceOf,
+			Args:     fmt.Sprintf("address:%v", owner),
+		}
+	}
+	callResults := tm.BatchReadContractContext(ctx, calls, nil)
+
+	results := make([]BalanceResult, len(owners))
+	for i, owner := range owners {
+		results[i] = BalanceResult{Address: owner, Err: callResults[i].Err}
+		if results[i].Err != nil {
+			continue
+		}
+		values := callResults[i].Values
+		if balance, ok := firstBigInt(values); ok {
+			results[i].Balance = balance
+		} else {
+			results[i].Err = &ReturnTypeError{Method: MethodBalanceOf, Want: "*big.Int", Got: values}
+		}
+	}
+	return results
+}
+
+func firstBigInt(values []interface{}) (*big.Int, bool) {
+	if len(values) != 1 {
+		return nil, false
+	}
+	n, ok := values[0].(*big.Int)
+	return n, ok
+}
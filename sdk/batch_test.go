@@ -0,0 +1,149 @@
+package sdk
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// newBatchTestBackend starts a testBackend of batch tests, balance and nonce of an address is its last byte,
+// eth_call answers balanceOf(owner) with the last byte of owner, and reverts when it's 0xff
+func newBatchTestBackend(t *testing.T) *testBackend {
+	backend := newTestBackend(t)
+	backend.set(func(b *testBackend) {
+		b.balance = func(address common.Address, block string) *big.Int {
+			return big.NewInt(int64(address[19]) * 1000)
+		}
+		b.nonce = func(address common.Address, block string) uint64 {
+			if block == "pending" {
+				return uint64(address[19]) + 1
+			}
+			return uint64(address[19])
+		}
+		b.call = func(args testCallArgs, block string) ([]byte, error) {
+			owner := args.Data[len(args.Data)-1]
+			if owner == 0xff {
+				return nil, errors.New("execution reverted")
+			}
+			return common.LeftPadBytes([]byte{owner}, 32), nil
+		}
+		for _, hash := range []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")} {
+			b.receipts[hash] = &types.Receipt{
+				TxHash:      hash,
+				Status:      types.ReceiptStatusSuccessful,
+				BlockNumber: big.NewInt(1),
+				GasUsed:     21000,
+				Logs:        []*types.Log{},
+			}
+		}
+	})
+	return backend
+}
+
+func newBatchTestTM(t *testing.T, backend *testBackend) (*TransactionManager, func()) {
+	tm, closeFn := backend.newTM(t)
+	tm.SetBatchSize(3)
+	return tm, closeFn
+}
+
+func TestBatchGetBalance(t *testing.T) {
+	backend := newBatchTestBackend(t)
+	defer backend.close()
+	tm, closeFn := newBatchTestTM(t, backend)
+	defer closeFn()
+
+	var addresses []string
+	for i := 1; i <= 7; i++ {
+		addresses = append(addresses, testAddress(byte(i)))
+	}
+	results := tm.BatchGetBalance(addresses)
+	if len(results) != 7 {
+		t.Fatalf("%v results", len(results))
+	}
+	for i, r := range results {
+		if r.Err != nil || r.Address != addresses[i] || r.Balance.Int64() != int64(i+1)*1000 {
+			t.Fatalf("result %v: %+v", i, r)
+		}
+	}
+	// 3 + 3 + 1
+	if n := backend.requestCount(); n != 3 {
+		t.Fatalf("%v requests", n)
+	}
+
+	nonces := tm.BatchPendingNonce(addresses[:2])
+	if nonces[0].Nonce != 2 || nonces[1].Nonce != 3 || nonces[0].Err != nil {
+		t.Fatalf("pending nonces: %+v", nonces)
+	}
+	nonces = tm.BatchNonce(addresses[:2])
+	if nonces[0].Nonce != 1 || nonces[1].Nonce != 2 {
+		t.Fatalf("nonces: %+v", nonces)
+	}
+}
+
+func TestBatchReceipt(t *testing.T) {
+	backend := newBatchTestBackend(t)
+	defer backend.close()
+	tm, closeFn := newBatchTestTM(t, backend)
+	defer closeFn()
+
+	results := tm.BatchReceipt([]string{"0x01", "0x00", "0x02"})
+	if results[0].Err != nil || results[0].Receipt.TxHash != common.HexToHash("0x01") || results[0].Receipt.GasUsed != 21000 {
+		t.Fatalf("receipt 0: %+v", results[0])
+	}
+	if results[1].Err != ethereum.NotFound || results[1].Receipt != nil {
+		t.Fatalf("receipt 1: %+v", results[1])
+	}
+	if results[2].Err != nil || results[2].Hash != "0x02" {
+		t.Fatalf("receipt 2: %+v", results[2])
+	}
+}
+
+func TestBatchReadContract(t *testing.T) {
+	backend := newBatchTestBackend(t)
+	defer backend.close()
+	tm, closeFn := newBatchTestTM(t, backend)
+	defer closeFn()
+
+	token := testAddress(0xaa)
+	results := tm.BatchBalanceOf20(token, []string{testAddress(5), testAddress(0xff), testAddress(9)})
+	if results[0].Err != nil || results[0].Balance.Int64() != 5 {
+		t.Fatalf("balance 0: %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, ErrReverted) {
+		t.Fatalf("balance 1: %+v", results[1])
+	}
+	if results[2].Err != nil || results[2].Balance.Int64() != 9 {
+		t.Fatalf("balance 2: %+v", results[2])
+	}
+
+	calls := []ContractCall{
+		{Contract: token, ABI: ERC20_ABI, Method: MethodBalanceOf, Args: "address:" + testAddress(7)},
+		{Contract: token, ABI: ERC20_ABI, Method: MethodBalanceOf, Args: "uint256:abc"},
+	}
+	callResults := tm.BatchReadContract(calls, big.NewInt(10))
+	if callResults[0].Err != nil || len(callResults[0].Output) != 32 || callResults[0].Values[0].(*big.Int).Int64() != 7 {
+		t.Fatalf("call 0: %+v", callResults[0])
+	}
+	var argErr *ArgError
+	if !errors.As(callResults[1].Err, &argErr) {
+		t.Fatalf("call 1: %+v", callResults[1])
+	}
+}
+
+func TestBatchTransportError(t *testing.T) {
+	backend := newBatchTestBackend(t)
+	tm, closeFn := newBatchTestTM(t, backend)
+	defer closeFn()
+	backend.close()
+
+	results := tm.BatchGetBalance([]string{testAddress(1), testAddress(2)})
+	for i, r := range results {
+		if r.Err == nil {
+			t.Fatalf("result %v: expect error", i)
+		}
+	}
+}
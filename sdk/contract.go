@@ -8,57 +8,72 @@ package sdk
 
 import (
 	"context"
-	"fmt"
 	"math/big"
-	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 func (tm *TransactionManager) SendCallMsgTx(toAddr string, data []byte, blockNumber *big.Int) ([]byte, error) {
+	return tm.SendCallMsgTxContext(context.Background(), toAddr, data, blockNumber)
+}
+
+// SendCallMsgTxContext is like SendCallMsgTx but with ctx for cancellation and deadline
+func (tm *TransactionManager) SendCallMsgTxContext(ctx context.Context, toAddr string, data []byte, blockNumber *big.Int) ([]byte, error) {
 	to := common.HexToAddress(toAddr)
 
 	msg := ethereum.CallMsg{
 		To:   &to,
 		Data: data,
 	}
-	return tm.Client.CallContract(context.Background(), msg, blockNumber)
+	var output []byte
+	err := tm.retry.Do(ctx, func() (err error) {
+		output, err = tm.Client.CallContract(ctx, msg, blockNumber)
+		return
+	})
+	return output, err
 }
 
 // CreateContract creates a contract,return tx's hash,use it to query contract address
-func (tm *TransactionManager) CreateContract(sk string, data []byte, gasPrice uint64, nonce uint64, gasLimit uint64) (string, error) {
-	return tm.SendTx(sk, "", nil, data, gasPrice, nonce, gasLimit)
+func (tm *TransactionManager) CreateContract(signer Signer, data []byte, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, error) {
+	return tm.CreateContractContext(context.Background(), signer, data, gasPrice, nonce, gasLimit)
+}
+
+// CreateContractContext is like CreateContract but with ctx for cancellation and deadline
+func (tm *TransactionManager) CreateContractContext(ctx context.Context, signer Signer, data []byte, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, error) {
+	return tm.SendTxContext(ctx, signer, "", nil, data, gasPrice, nonce, gasLimit)
 }
 
 // CreateContractSync creates a contract syncly, return contract address ,tx hash ,gas used, error
 // set timeout to 0 to use default timeout value
-func (tm *TransactionManager) CreateContractSync(sk string, data []byte, gasPrice uint64, nonce uint64, gasLimit uint64) (string, string, uint64, error) {
-	hash, err := tm.CreateContract(sk, data, gasPrice, nonce, gasLimit)
+func (tm *TransactionManager) CreateContractSync(signer Signer, data []byte, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, string, uint64, error) {
+	return tm.CreateContractSyncContext(context.Background(), signer, data, gasPrice, nonce, gasLimit)
+}
+
+// CreateContractSyncContext is like CreateContractSync but with ctx for cancellation and deadline
+func (tm *TransactionManager) CreateContractSyncContext(ctx context.Context, signer Signer, data []byte, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, string, uint64, error) {
+	hash, err := tm.CreateContractContext(ctx, signer, data, gasPrice, nonce, gasLimit)
 	if err != nil {
 		return "", "", 0, err
 	}
 
-	deadline := time.After(time.Second * time.Duration(tm.timeout))
-	tick := time.Tick(time.Second * time.Duration(tm.interval))
-	for {
-		select {
-		case <-deadline:
-			return "", "", 0, fmt.Errorf("timeout")
-		case <-tick:
-			receipt, err := tm.Client.TransactionReceipt(context.Background(), common.HexToHash(hash))
-			if err != nil {
-				// skip
-			} else {
-				return receipt.ContractAddress.String(), hash, receipt.GasUsed, nil
-			}
-		}
+	receipt, err := tm.waitSuccess(ctx, hash, "")
+	if receipt == nil {
+		return "", "", 0, err
 	}
-
+	if err != nil {
+		return "", hash, receipt.GasUsed, err
+	}
+	return receipt.ContractAddress.String(), hash, receipt.GasUsed, nil
 }
 
 func (tm *TransactionManager) GetContractAddress(hash string) (string, error) {
-	receipt, err := tm.Client.TransactionReceipt(context.Background(), common.HexToHash(hash))
+	return tm.GetContractAddressContext(context.Background(), hash)
+}
+
+// GetContractAddressContext is like GetContractAddress but with ctx for cancellation and deadline
+func (tm *TransactionManager) GetContractAddressContext(ctx context.Context, hash string) (string, error) {
+	receipt, err := tm.ReceiptContext(ctx, hash)
 	if err != nil {
 		return "", err
 	}
@@ -66,73 +81,67 @@ func (tm *TransactionManager) GetContractAddress(hash string) (string, error) {
 }
 
 func (tm *TransactionManager) GetContractAddressSync(hash string) (string, error) {
-	deadline := time.After(time.Second * time.Duration(tm.timeout))
-	tick := time.Tick(time.Second * time.Duration(tm.interval))
-
-	for {
-		select {
-		case <-deadline:
-			return "", fmt.Errorf("timeout")
-		case <-tick:
-			receipt, err := tm.Client.TransactionReceipt(context.Background(), common.HexToHash(hash))
-			if err != nil {
-				// skip
-			} else {
-				return receipt.ContractAddress.String(), nil
-			}
-		}
-	}
-
+	return tm.GetContractAddressSyncContext(context.Background(), hash)
 }
 
-// WriteContract sends an async write contract,return hash,error
-func (tm *TransactionManager) WriteContract(sk string, contractAddress string, v *big.Int, abi string, methodName, args string, gasPrice uint64, nonce uint64, gasLimit uint64) (string, error) {
-	payload, err := Pack(abi, methodName, args)
+// GetContractAddressSyncContext is like GetContractAddressSync but with ctx for cancellation and deadline
+func (tm *TransactionManager) GetContractAddressSyncContext(ctx context.Context, hash string) (string, error) {
+	receipt, err := tm.waitSuccess(ctx, hash, "")
 	if err != nil {
 		return "", err
 	}
-	hash, err := tm.SendTx(sk, contractAddress, v, payload, gasPrice, nonce, gasLimit)
+	return receipt.ContractAddress.String(), nil
+}
+
+// WriteContract sends an async write contract,return hash,error
+func (tm *TransactionManager) WriteContract(signer Signer, contractAddress string, v *big.Int, abi string, methodName, args string, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, error) {
+	return tm.WriteContractContext(context.Background(), signer, contractAddress, v, abi, methodName, args, gasPrice, nonce, gasLimit)
+}
+
+// WriteContractContext is like WriteContract but with ctx for cancellation and deadline
+func (tm *TransactionManager) WriteContractContext(ctx context.Context, signer Signer, contractAddress string, v *big.Int, abi string, methodName, args string, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, error) {
+	payload, err := Pack(abi, methodName, args)
 	if err != nil {
 		return "", err
 	}
-	return hash, nil
+	// custom errors of abi are decoded when gas estimation or simulation reverts
+	return tm.sendTx(ctx, signer, contractAddress, v, payload, nil, abi, gasPrice, nonce, gasLimit)
 }
 
 // WriteContractSync sends an sync write contract,return hash, gas used, error
-func (tm *TransactionManager) WriteContractSync(sk string, contractAddress string, v *big.Int, abi string, methodName, args string, gasPrice uint64, nonce uint64, gasLimit uint64) (string, uint64, error) {
-	hash, err := tm.WriteContract(sk, contractAddress, v, abi, methodName, args, gasPrice, nonce, gasLimit)
+// error is *RevertError when tx is reverted, its reason is decoded with custom errors in abi
+func (tm *TransactionManager) WriteContractSync(signer Signer, contractAddress string, v *big.Int, abi string, methodName, args string, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, uint64, error) {
+	return tm.WriteContractSyncContext(context.Background(), signer, contractAddress, v, abi, methodName, args, gasPrice, nonce, gasLimit)
+}
+
+// WriteContractSyncContext is like WriteContractSync but with ctx for cancellation and deadline
+func (tm *TransactionManager) WriteContractSyncContext(ctx context.Context, signer Signer, contractAddress string, v *big.Int, abi string, methodName, args string, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, uint64, error) {
+	hash, err := tm.WriteContractContext(ctx, signer, contractAddress, v, abi, methodName, args, gasPrice, nonce, gasLimit)
 	if err != nil {
 		return "", 0, err
 	}
 
-	deadline := time.After(time.Second * time.Duration(tm.timeout))
-	tick := time.Tick(time.Second * time.Duration(tm.interval))
-
-	for {
-		select {
-		case <-deadline:
-			return "", 0, fmt.Errorf("timeout")
-		case <-tick:
-			receipt, err := tm.Client.TransactionReceipt(context.Background(), common.HexToHash(hash))
-			if err != nil {
-				// skip
-			} else {
-				return hash, receipt.GasUsed, nil
-			}
-		}
+	receipt, err := tm.waitSuccess(ctx, hash, abi)
+	if receipt == nil {
+		return "", 0, err
 	}
+	return hash, receipt.GasUsed, err
 }
 
 // ReadContract send a call msg tx to contract, set blockNumber to nil for latest block
 func (tm *TransactionManager) ReadContract(contractAddress string, abi string, methodName, args string, blockNumber *big.Int) ([]byte, error) {
+	return tm.ReadContractContext(context.Background(), contractAddress, abi, methodName, args, blockNumber)
+}
+
+// ReadContractContext is like ReadContract but with ctx for cancellation and deadline
+func (tm *TransactionManager) ReadContractContext(ctx context.Context, contractAddress string, abi string, methodName, args string, blockNumber *big.Int) ([]byte, error) {
 	payload, err := Pack(abi, methodName, args)
 	if err != nil {
 		return nil, err
 	}
-	output, err := tm.SendCallMsgTx(contractAddress, payload, blockNumber)
+	output, err := tm.SendCallMsgTxContext(ctx, contractAddress, payload, blockNumber)
 	if err != nil {
 		return nil, err
 	}
 	return output, nil
 }
-
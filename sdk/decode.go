@@ -0,0 +1,147 @@
+package sdk
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DecodedTx is a raw signed transaction decoded by DecodeRawTx
+type DecodedTx struct {
+	Type     uint8 // types.LegacyTxType, types.AccessListTxType or types.DynamicFeeTxType
+	Hash     string
+	ChainID  *big.Int // nil for legacy tx without EIP-155 replay protection
+	Nonce    uint64
+	From     string // recovered from signature
+	To       string // empty for contract creation
+	Value    *big.Int
+	Data     []byte
+	GasLimit uint64
+
+	GasPrice   *big.Int // gas fee cap for EIP-1559 transaction
+	GasTipCap  *big.Int // same as GasPrice for legacy and EIP-2930 transaction
+	GasFeeCap  *big.Int // same as GasPrice for legacy and EIP-2930 transaction
+	AccessList types.AccessList
+
+	Tx *types.Transaction
+}
+
+// DecodedArg is an argument of DecodedCall
+type DecodedArg struct {
+	Name  string
+	Type  string
+	Value interface{}
+}
+
+// DecodedCall is calldata decoded by DecodeCalldata
+type DecodedCall struct {
+	Method    string // name of method, e.g. transfer
+	Signature string // e.g. transfer(address,uint256)
+	Args      []DecodedArg
+}
+
+// String returns the call like transfer(_to: 0x1234..., _value: 100)
+func (c *DecodedCall) String() string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = fmt.Sprintf("%v: %v", arg.Name, formatArgValue(arg.Value))
+	}
+	return fmt.Sprintf("%v(%v)", c.Method, strings.Join(args, ", "))
+}
+
+// formatArgValue prints bytes and addresses in hex
+func formatArgValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case common.Address:
+		return v.String()
+	case []common.Address:
+		addresses := make([]string, len(v))
+		for i := range v {
+			addresses[i] = v[i].String()
+		}
+		return "[" + strings.Join(addresses, " ") + "]"
+	case []byte:
+		return hexutil.Encode(v)
+	case [32]byte:
+		return hexutil.Encode(v[:])
+	}
+	return value
+}
+
+// DecodeRawTx decodes raw signed transaction in 0x hex, both legacy and typed (EIP-2930, EIP-1559) ones,
+// and recovers its sender
+func DecodeRawTx(raw string) (*DecodedTx, error) {
+	data, err := hexutil.Decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode raw transaction error: %s", err.Error())
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("decode raw transaction error: %s", err.Error())
+	}
+
+	var chainID *big.Int
+	if tx.Protected() {
+		chainID = tx.ChainId()
+	}
+	from, err := recoverSender(tx)
+	if err != nil {
+		return nil, fmt.Errorf("recover sender error: %s", err.Error())
+	}
+	decoded := &DecodedTx{
+		Type:       tx.Type(),
+		Hash:       tx.Hash().String(),
+		ChainID:    chainID,
+		Nonce:      tx.Nonce(),
+		From:       from.String(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		GasLimit:   tx.Gas(),
+		GasPrice:   tx.GasPrice(),
+		GasTipCap:  tx.GasTipCap(),
+		GasFeeCap:  tx.GasFeeCap(),
+		AccessList: tx.AccessList(),
+		Tx:         tx,
+	}
+	if tx.To() != nil {
+		decoded.To = tx.To().String()
+	}
+	return decoded, nil
+}
+
+// DecodeData decodes data of the transaction with abiStr, such as ERC20_ABI
+func (d *DecodedTx) DecodeData(abiStr string) (*DecodedCall, error) {
+	if d.To == "" {
+		return nil, fmt.Errorf("transaction %v creates contract", d.Hash)
+	}
+	return DecodeCalldata(abiStr, d.Data)
+}
+
+// DecodeCalldata decodes input of a contract call with abiStr, the method is found by selector of data
+func DecodeCalldata(abiStr string, data []byte) (*DecodedCall, error) {
+	abiObj, _, err := parseABI(abiStr)
+	if err != nil {
+		return nil, fmt.Errorf("abi.JSON error: %v", err)
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("calldata of %v bytes has no method selector", len(data))
+	}
+	method, err := abiObj.MethodById(data[:4])
+	if err != nil {
+		return nil, err
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("unpack arguments of %v error: %s", method.Sig, err.Error())
+	}
+
+	call := &DecodedCall{Method: method.RawName, Signature: method.Sig}
+	for i, input := range method.Inputs {
+		call.Args = append(call.Args, DecodedArg{Name: input.Name, Type: input.Type.String(), Value: values[i]})
+	}
+	return call, nil
+}
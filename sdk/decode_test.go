@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDecodeRawTx(t *testing.T) {
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := testAddress(0xaa)
+	data, err := Pack(ERC20_ABI, MethodTransfer, "address:"+testAddress(1)+";uint256:100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []TxParams{
+		{ChainID: big.NewInt(5), Nonce: 1, To: token, GasLimit: 60000, GasPrice: big.NewInt(1e9), Data: data},
+		{Nonce: 2, To: token, GasLimit: 60000, GasPrice: big.NewInt(1e9), Data: data},
+		{ChainID: big.NewInt(5), Nonce: 3, To: token, GasLimit: 60000, GasPrice: big.NewInt(1e9), Data: data, AccessList: types.AccessList{}},
+		{ChainID: big.NewInt(5), Nonce: 4, To: token, Value: big.NewInt(7), GasLimit: 60000, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9), Data: data},
+	}
+	txTypes := []uint8{types.LegacyTxType, types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType}
+	for i, params := range cases {
+		signed, err := BuildSignedTx(signer, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := DecodeRawTx(signed.Raw)
+		if err != nil {
+			t.Fatalf("case %v: %v", i, err)
+		}
+		if decoded.Type != txTypes[i] || decoded.Hash != signed.Hash || decoded.From != signer.Address().String() ||
+			decoded.To != token || decoded.Nonce != params.Nonce || decoded.GasLimit != params.GasLimit {
+			t.Fatalf("case %v: %+v", i, decoded)
+		}
+		if (params.ChainID == nil) != (decoded.ChainID == nil) || params.ChainID != nil && params.ChainID.Cmp(decoded.ChainID) != 0 {
+			t.Fatalf("case %v: chain id %v", i, decoded.ChainID)
+		}
+		if params.GasFeeCap != nil && (decoded.GasFeeCap.Cmp(params.GasFeeCap) != 0 || decoded.GasTipCap.Cmp(params.GasTipCap) != 0 || decoded.Value.Int64() != 7) {
+			t.Fatalf("case %v: fees %v %v", i, decoded.GasTipCap, decoded.GasFeeCap)
+		}
+		if params.GasPrice != nil && decoded.GasPrice.Cmp(params.GasPrice) != 0 {
+			t.Fatalf("case %v: gas price %v", i, decoded.GasPrice)
+		}
+
+		call, err := decoded.DecodeData(ERC20_ABI)
+		if err != nil {
+			t.Fatalf("case %v: %v", i, err)
+		}
+		if call.Method != MethodTransfer || call.Signature != "transfer(address,uint256)" || len(call.Args) != 2 {
+			t.Fatalf("case %v: %+v", i, call)
+		}
+		if want := "transfer(_to: " + testAddress(1) + ", _amount: 100)"; call.String() != want {
+			t.Fatalf("case %v: %v, want %v", i, call.String(), want)
+		}
+	}
+
+	if _, err := DecodeRawTx("0x1234"); err == nil {
+		t.Fatal("expect error of invalid raw transaction")
+	}
+}
+
+func TestDecodeCalldata(t *testing.T) {
+	data, err := Pack(ERC721_ABI, "safeTransferFrom0", "address:"+testAddress(1)+";address:"+testAddress(2)+";uint256:3;bytes:0x0102")
+	if err != nil {
+		t.Fatal(err)
+	}
+	call, err := DecodeCalldata(ERC721_ABI, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.Signature != "safeTransferFrom(address,address,uint256,bytes)" || call.Args[2].Value.(*big.Int).Int64() != 3 {
+		t.Fatalf("%+v", call)
+	}
+	if want := "safeTransferFrom(_from: " + testAddress(1) + ", _to: " + testAddress(2) + ", _tokenId: 3, data: 0x0102)"; call.String() != want {
+		t.Fatalf("%v, want %v", call.String(), want)
+	}
+
+	// selector of other abi
+	if _, err := DecodeCalldata(ERC20_ABI, data); err == nil {
+		t.Fatal("expect error of unknown selector")
+	}
+	if _, err := DecodeCalldata(ERC20_ABI, data[:3]); err == nil {
+		t.Fatal("expect error of short calldata")
+	}
+	if _, err := DecodeCalldata(ERC721_ABI, data[:40]); err == nil {
+		t.Fatal("expect error of truncated arguments")
+	}
+}
@@ -7,7 +7,7 @@
 package sdk
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"math/big"
 )
@@ -27,7 +27,12 @@ const (
 
 // Symbol20 ERC20 symbol
 func (tm *TransactionManager) Symbol20(contractAddress string) (string, error) {
-	raw, err := tm.ReadContract(contractAddress, ERC20_ABI, MethodSymbol, "", nil)
+	return tm.Symbol20Context(context.Background(), contractAddress)
+}
+
+// Symbol20Context is like Symbol20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) Symbol20Context(ctx context.Context, contractAddress string) (string, error) {
+	raw, err := tm.ReadContractContext(ctx, contractAddress, ERC20_ABI, MethodSymbol, "", nil)
 	if err != nil {
 		return "", err
 	}
@@ -39,15 +44,20 @@ func (tm *TransactionManager) Symbol20(contractAddress string) (string, error) {
 		if ret, ok := data[0].(string); ok {
 			return ret, nil
 		} else {
-			return "", errors.New("returned data not string")
+			return "", &ReturnTypeError{Method: MethodSymbol, Want: "string", Got: data}
 		}
 	}
-	return "", errors.New("symbol() returned data error")
+	return "", &ReturnTypeError{Method: MethodSymbol, Want: "string", Got: data}
 }
 
 // TotalSupply20 ERC20 totalSupply
 func (tm *TransactionManager) TotalSupply20(contractAddress string) (*big.Int, error) {
-	raw, err := tm.ReadContract(contractAddress, ERC20_ABI, MethodTotalSupply, "", nil)
+	return tm.TotalSupply20Context(context.Background(), contractAddress)
+}
+
+// TotalSupply20Context is like TotalSupply20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) TotalSupply20Context(ctx context.Context, contractAddress string) (*big.Int, error) {
+	raw, err := tm.ReadContractContext(ctx, contractAddress, ERC20_ABI, MethodTotalSupply, "", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -59,16 +69,21 @@ func (tm *TransactionManager) TotalSupply20(contractAddress string) (*big.Int, e
 		if ret, ok := data[0].(*big.Int); ok {
 			return ret, nil
 		} else {
-			return nil, errors.New("returned data not big.Int")
+			return nil, &ReturnTypeError{Method: MethodTotalSupply, Want: "*big.Int", Got: data}
 		}
 	}
-	return nil, errors.New("totalSupply() returned data error")
+	return nil, &ReturnTypeError{Method: MethodTotalSupply, Want: "*big.Int", Got: data}
 }
 
 // BalanceOf20 ERC20 balanceOf
 func (tm *TransactionManager) BalanceOf20(contractAddress string, owner string) (*big.Int, error) {
+	return tm.BalanceOf20Context(context.Background(), contractAddress, owner)
+}
+
+// BalanceOf20Context is like BalanceOf20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) BalanceOf20Context(ctx context.Context, contractAddress string, owner string) (*big.Int, error) {
 	args := fmt.Sprintf("address:%v", owner)
-	raw, err := tm.ReadContract(contractAddress, ERC20_ABI, MethodBalanceOf, args, nil)
+	raw, err := tm.ReadContractContext(ctx, contractAddress, ERC20_ABI, MethodBalanceOf, args, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -81,52 +96,87 @@ func (tm *TransactionManager) BalanceOf20(contractAddress string, owner string)
 		if ret, ok := data[0].(*big.Int); ok {
 			return ret, nil
 		} else {
-			return nil, errors.New("returned data not big.Int")
+			return nil, &ReturnTypeError{Method: MethodBalanceOf, Want: "*big.Int", Got: data}
 		}
 	}
-	return nil, errors.New("balanceOf() returned data error")
+	return nil, &ReturnTypeError{Method: MethodBalanceOf, Want: "*big.Int", Got: data}
 }
 
 // Transfer20 ERC20 transfer
-func (tm *TransactionManager) Transfer20(contractAddress string, sk string, to string, value string, price uint64, nonce uint64, limit uint64) (string, error) {
+func (tm *TransactionManager) Transfer20(contractAddress string, signer Signer, to string, value string, price *big.Int, nonce uint64, limit uint64) (string, error) {
+	return tm.Transfer20Context(context.Background(), contractAddress, signer, to, value, price, nonce, limit)
+}
+
+// Transfer20Context is like Transfer20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) Transfer20Context(ctx context.Context, contractAddress string, signer Signer, to string, value string, price *big.Int, nonce uint64, limit uint64) (string, error) {
 	args := fmt.Sprintf("address:%v;uint256:%v", to, value)
-	return tm.WriteContract(sk, contractAddress, nil, ERC20_ABI, MethodTransfer, args, price, nonce, limit)
+	return tm.WriteContractContext(ctx, signer, contractAddress, nil, ERC20_ABI, MethodTransfer, args, price, nonce, limit)
 }
 
 // Transfer20 ERC20 transfer sync
-func (tm *TransactionManager) TransferSync20(contractAddress string, sk string, to string, value string, price uint64, nonce uint64, limit uint64) (string, uint64, error) {
+func (tm *TransactionManager) TransferSync20(contractAddress string, signer Signer, to string, value string, price *big.Int, nonce uint64, limit uint64) (string, uint64, error) {
+	return tm.TransferSync20Context(context.Background(), contractAddress, signer, to, value, price, nonce, limit)
+}
+
+// TransferSync20Context is like TransferSync20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) TransferSync20Context(ctx context.Context, contractAddress string, signer Signer, to string, value string, price *big.Int, nonce uint64, limit uint64) (string, uint64, error) {
 	args := fmt.Sprintf("address:%v;uint256:%v", to, value)
-	return tm.WriteContractSync(sk, contractAddress, nil, ERC20_ABI, MethodTransfer, args, price, nonce, limit)
+	return tm.WriteContractSyncContext(ctx, signer, contractAddress, nil, ERC20_ABI, MethodTransfer, args, price, nonce, limit)
 }
 
 // Approve20 ERC20 approve
-func (tm *TransactionManager) Approve20(contractAddress string, sk string, spender string, value string, price uint64, nonce uint64, limit uint64) (string, error) {
+func (tm *TransactionManager) Approve20(contractAddress string, signer Signer, spender string, value string, price *big.Int, nonce uint64, limit uint64) (string, error) {
+	return tm.Approve20Context(context.Background(), contractAddress, signer, spender, value, price, nonce, limit)
+}
+
+// Approve20Context is like Approve20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) Approve20Context(ctx context.Context, contractAddress string, signer Signer, spender string, value string, price *big.Int, nonce uint64, limit uint64) (string, error) {
 	args := fmt.Sprintf("address:%v;uint256:%v", spender, value)
-	return tm.WriteContract(sk, contractAddress, nil, ERC20_ABI, MethodApprove, args, price, nonce, limit)
+	return tm.WriteContractContext(ctx, signer, contractAddress, nil, ERC20_ABI, MethodApprove, args, price, nonce, limit)
 }
 
 // Approve20 ERC20 approve sync
-func (tm *TransactionManager) ApproveSync20(contractAddress string, sk string, spender string, value string, price uint64, nonce uint64, limit uint64) (string, uint64, error) {
+func (tm *TransactionManager) ApproveSync20(contractAddress string, signer Signer, spender string, value string, price *big.Int, nonce uint64, limit uint64) (string, uint64, error) {
+	return tm.ApproveSync20Context(context.Background(), contractAddress, signer, spender, value, price, nonce, limit)
+}
+
+// ApproveSync20Context is like ApproveSync20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) ApproveSync20Context(ctx context.Context, contractAddress string, signer Signer, spender string, value string, price *big.Int, nonce uint64, limit uint64) (string, uint64, error) {
 	args := fmt.Sprintf("address:%v;uint256:%v", spender, value)
-	return tm.WriteContractSync(sk, contractAddress, nil, ERC20_ABI, MethodApprove, args, price, nonce, limit)
+	return tm.WriteContractSyncContext(ctx, signer, contractAddress, nil, ERC20_ABI, MethodApprove, args, price, nonce, limit)
 }
 
 // TransferFrom20 ERC20 transferFrom
-func (tm *TransactionManager) TransferFrom20(contractAddress string, sk string, from string, to string, value string, price uint64, nonce uint64, limit uint64) (string, error) {
+func (tm *TransactionManager) TransferFrom20(contractAddress string, signer Signer, from string, to string, value string, price *big.Int, nonce uint64, limit uint64) (string, error) {
+	return tm.TransferFrom20Context(context.Background(), contractAddress, signer, from, to, value, price, nonce, limit)
+}
+
+// TransferFrom20Context is like TransferFrom20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) TransferFrom20Context(ctx context.Context, contractAddress string, signer Signer, from string, to string, value string, price *big.Int, nonce uint64, limit uint64) (string, error) {
 	args := fmt.Sprintf("address:%v;address:%v;uint256:%v", from, to, value)
-	return tm.WriteContract(sk, contractAddress, nil, ERC20_ABI, MethodTransferFrom, args, price, nonce, limit)
+	return tm.WriteContractContext(ctx, signer, contractAddress, nil, ERC20_ABI, MethodTransferFrom, args, price, nonce, limit)
 }
 
 // TransferFrom20 ERC20 transferFrom sync
-func (tm *TransactionManager) TransferFromSync20(contractAddress string, sk string, from string, to string, value string, price uint64, nonce uint64, limit uint64) (string, uint64, error) {
+func (tm *TransactionManager) TransferFromSync20(contractAddress string, signer Signer, from string, to string, value string, price *big.Int, nonce uint64, limit uint64) (string, uint64, error) {
+	return tm.TransferFromSync20Context(context.Background(), contractAddress, signer, from, to, value, price, nonce, limit)
+}
+
+// TransferFromSync20Context is like TransferFromSync20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) TransferFromSync20Context(ctx context.Context, contractAddress string, signer Signer, from string, to string, value string, price *big.Int, nonce uint64, limit uint64) (string, uint64, error) {
 	args := fmt.Sprintf("address:%v;address:%v;uint256:%v", from, to, value)
-	return tm.WriteContractSync(sk, contractAddress, nil, ERC20_ABI, MethodTransferFrom, args, price, nonce, limit)
+	return tm.WriteContractSyncContext(ctx, signer, contractAddress, nil, ERC20_ABI, MethodTransferFrom, args, price, nonce, limit)
 }
 
 // Allowance20 ERC20 allowance
 func (tm *TransactionManager) Allowance20(contractAddress string, owner string, spender string) (*big.Int, error) {
+	return tm.Allowance20Context(context.Background(), contractAddress, owner, spender)
+}
+
+// Allowance20Context is like Allowance20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) Allowance20Context(ctx context.Context, contractAddress string, owner string, spender string) (*big.Int, error) {
 	args := fmt.Sprintf("address:%v;address:%v", owner, spender)
-	raw, err := tm.ReadContract(contractAddress, ERC20_ABI, MethodAllowance, args, nil)
+	raw, err := tm.ReadContractContext(ctx, contractAddress, ERC20_ABI, MethodAllowance, args, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -140,15 +190,20 @@ func (tm *TransactionManager) Allowance20(contractAddress string, owner string,
 		if ret, ok := data[0].(*big.Int); ok {
 			return ret, nil
 		} else {
-			return nil, errors.New("returned data not big.Int")
+			return nil, &ReturnTypeError{Method: MethodAllowance, Want: "*big.Int", Got: data}
 		}
 	}
-	return nil, errors.New("allowance() returned data error")
+	return nil, &ReturnTypeError{Method: MethodAllowance, Want: "*big.Int", Got: data}
 }
 
 // Decimals20 ERC20 decimals
 func (tm *TransactionManager) Decimals20(contractAddress string) (uint8, error) {
-	raw, err := tm.ReadContract(contractAddress, ERC20_ABI, MethodDecimals, "", nil)
+	return tm.Decimals20Context(context.Background(), contractAddress)
+}
+
+// Decimals20Context is like Decimals20 but with ctx for cancellation and deadline
+func (tm *TransactionManager) Decimals20Context(ctx context.Context, contractAddress string) (uint8, error) {
+	raw, err := tm.ReadContractContext(ctx, contractAddress, ERC20_ABI, MethodDecimals, "", nil)
 	if err != nil {
 		return 0, err
 	}
@@ -161,8 +216,8 @@ func (tm *TransactionManager) Decimals20(contractAddress string) (uint8, error)
 		if ret, ok := data[0].(uint8); ok {
 			return ret, nil
 		} else {
-			return 0, errors.New("returned data not uint8")
+			return 0, &ReturnTypeError{Method: MethodDecimals, Want: "uint8", Got: data}
 		}
 	}
-	return 0, errors.New("decimals() returned data error")
+	return 0, &ReturnTypeError{Method: MethodDecimals, Want: "uint8", Got: data}
 }
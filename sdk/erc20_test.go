@@ -7,6 +7,7 @@
 package sdk
 
 import (
+	"math/big"
 	"testing"
 )
 
@@ -16,7 +17,7 @@ func TestTransactionManager_TotalSupply(t *testing.T) {
 func TestBalanceOf(t *testing.T) {
 	// abiStr := `[{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"payable":false,"type":"function","stateMutability":"view"},{"constant":false,"inputs":[{"name":"_spender","type":"address"},{"name":"_amount","type":"uint256"}],"name":"approve","outputs":[{"name":"success","type":"bool"}],"payable":false,"type":"function","stateMutability":"nonpayable"},{"constant":true,"inputs":[],"name":"totalSupply","outputs":[{"name":"totalSupply","type":"uint256"}],"payable":false,"type":"function","stateMutability":"view"},{"constant":false,"inputs":[{"name":"_from","type":"address"},{"name":"_to","type":"address"},{"name":"_amount","type":"uint256"}],"name":"transferFrom","outputs":[{"name":"success","type":"bool"}],"payable":false,"type":"function","stateMutability":"nonpayable"},{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"balance","type":"uint256"}],"payable":false,"type":"function","stateMutability":"view"},{"constant":false,"inputs":[],"name":"destroy","outputs":[{"name":"success","type":"bool"}],"payable":false,"type":"function","stateMutability":"nonpayable"},{"constant":true,"inputs":[],"name":"owner","outputs":[{"name":"","type":"address"}],"payable":false,"type":"function","stateMutability":"view"},{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"payable":false,"type":"function","stateMutability":"view"},{"constant":false,"inputs":[{"name":"_to","type":"address"},{"name":"_amount","type":"uint256"}],"name":"transfer","outputs":[{"name":"success","type":"bool"}],"payable":false,"type":"function","stateMutability":"nonpayable"},{"constant":true,"inputs":[{"name":"_owner","type":"address"},{"name":"_spender","type":"address"}],"name":"allowance","outputs":[{"name":"remaining","type":"uint256"}],"payable":false,"type":"function","stateMutability":"view"},{"inputs":[],"payable":false,"type":"constructor","stateMutability":"nonpayable"},{"anonymous":false,"inputs":[{"indexed":true,"name":"_from","type":"address"},{"indexed":true,"name":"_to","type":"address"},{"indexed":false,"name":"_value","type":"uint256"}],"name":"Transfer20","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"name":"_owner","type":"address"},{"indexed":true,"name":"_spender","type":"address"},{"indexed":false,"name":"_value","type":"uint256"}],"name":"Approval","type":"event"}]`
 	var (
-		price uint64 = 0
+		price *big.Int
 		limit uint64 = 0
 
 		// rpc string = "https://bsc-dataseed1.defibit.io/"
@@ -60,7 +61,7 @@ func TestBalanceOf(t *testing.T) {
 	}
 	t.Logf("totalSupply: %v", total)
 
-	hash, _, err := tm.ApproveSync20(contractAddress, sk0, spender, "100", 10000000, 0, 1000000)
+	hash, _, err := tm.ApproveSync20(contractAddress, hexSigner(t, sk0), spender, "100", big.NewInt(10000000), 0, 1000000)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -72,7 +73,7 @@ func TestBalanceOf(t *testing.T) {
 	}
 	t.Logf("allowlance: %x", allowance)
 
-	hash, _, err = tm.TransferFromSync20(contractAddress, spenderSk, addr0, to, "100", 10000000, 0, 1000000)
+	hash, _, err = tm.TransferFromSync20(contractAddress, hexSigner(t, spenderSk), addr0, to, "100", big.NewInt(10000000), 0, 1000000)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -7,7 +7,9 @@
 package sdk
 
 import (
+	"context"
 	"fmt"
+	"math/big"
 )
 
 const (
@@ -25,25 +27,45 @@ const (
 )
 
 // TransferFrom721 send erc721 transferFrom interface
-func (tm *TransactionManager) TransferFrom721(contractAddress string, sk string, from string, to string, tokenId string, price uint64, nonce uint64, limit uint64) (string, error) {
+func (tm *TransactionManager) TransferFrom721(contractAddress string, signer Signer, from string, to string, tokenId string, price *big.Int, nonce uint64, limit uint64) (string, error) {
+	return tm.TransferFrom721Context(context.Background(), contractAddress, signer, from, to, tokenId, price, nonce, limit)
+}
+
+// TransferFrom721Context is like TransferFrom721 but with ctx for cancellation and deadline
+func (tm *TransactionManager) TransferFrom721Context(ctx context.Context, contractAddress string, signer Signer, from string, to string, tokenId string, price *big.Int, nonce uint64, limit uint64) (string, error) {
 	args := fmt.Sprintf("address:%v;address:%v;uint256:%v", from, to, tokenId)
-	return tm.WriteContract(sk, contractAddress, nil, ERC721_ABI, MethodTransferFrom721, args, price, nonce, limit)
+	return tm.WriteContractContext(ctx, signer, contractAddress, nil, ERC721_ABI, MethodTransferFrom721, args, price, nonce, limit)
 }
 
 // TransferFromSync721 send erc721 transferFrom interface
-func (tm *TransactionManager) TransferFromSync721(contractAddress string, sk string, from string, to string, tokenId string, price uint64, nonce uint64, limit uint64) (string, uint64, error) {
+func (tm *TransactionManager) TransferFromSync721(contractAddress string, signer Signer, from string, to string, tokenId string, price *big.Int, nonce uint64, limit uint64) (string, uint64, error) {
+	return tm.TransferFromSync721Context(context.Background(), contractAddress, signer, from, to, tokenId, price, nonce, limit)
+}
+
+// TransferFromSync721Context is like TransferFromSync721 but with ctx for cancellation and deadline
+func (tm *TransactionManager) TransferFromSync721Context(ctx context.Context, contractAddress string, signer Signer, from string, to string, tokenId string, price *big.Int, nonce uint64, limit uint64) (string, uint64, error) {
 	args := fmt.Sprintf("address:%v;address:%v;uint256:%v", from, to, tokenId)
-	return tm.WriteContractSync(sk, contractAddress, nil, ERC721_ABI, MethodTransferFrom721, args, price, nonce, limit)
+	return tm.WriteContractSyncContext(ctx, signer, contractAddress, nil, ERC721_ABI, MethodTransferFrom721, args, price, nonce, limit)
 }
 
 // SafeTransferFrom721 send erc721 transferFrom interface
-func (tm *TransactionManager) SafeTransferFrom721(contractAddress string, sk string, from string, to string, tokenId string, price uint64, nonce uint64, limit uint64) (string, error) {
+func (tm *TransactionManager) SafeTransferFrom721(contractAddress string, signer Signer, from string, to string, tokenId string, price *big.Int, nonce uint64, limit uint64) (string, error) {
+	return tm.SafeTransferFrom721Context(context.Background(), contractAddress, signer, from, to, tokenId, price, nonce, limit)
+}
+
+// SafeTransferFrom721Context is like SafeTransferFrom721 but with ctx for cancellation and deadline
+func (tm *TransactionManager) SafeTransferFrom721Context(ctx context.Context, contractAddress string, signer Signer, from string, to string, tokenId string, price *big.Int, nonce uint64, limit uint64) (string, error) {
 	args := fmt.Sprintf("address:%v;address:%v;uint256:%v", from, to, tokenId)
-	return tm.WriteContract(sk, contractAddress, nil, ERC721_ABI, MethodSafeTransferFrom721, args, price, nonce, limit)
+	return tm.WriteContractContext(ctx, signer, contractAddress, nil, ERC721_ABI, MethodSafeTransferFrom721, args, price, nonce, limit)
 }
 
 // SafeTransferFromSync721 send erc721 transferFrom interface
-func (tm *TransactionManager) SafeTransferFromSync721(contractAddress string, sk string, from string, to string, tokenId string, price uint64, nonce uint64, limit uint64) (string, uint64, error) {
+func (tm *TransactionManager) SafeTransferFromSync721(contractAddress string, signer Signer, from string, to string, tokenId string, price *big.Int, nonce uint64, limit uint64) (string, uint64, error) {
+	return tm.SafeTransferFromSync721Context(context.Background(), contractAddress, signer, from, to, tokenId, price, nonce, limit)
+}
+
+// SafeTransferFromSync721Context is like SafeTransferFromSync721 but with ctx for cancellation and deadline
+func (tm *TransactionManager) SafeTransferFromSync721Context(ctx context.Context, contractAddress string, signer Signer, from string, to string, tokenId string, price *big.Int, nonce uint64, limit uint64) (string, uint64, error) {
 	args := fmt.Sprintf("address:%v;address:%v;uint256:%v", from, to, tokenId)
-	return tm.WriteContractSync(sk, contractAddress, nil, ERC721_ABI, MethodSafeTransferFrom721, args, price, nonce, limit)
+	return tm.WriteContractSyncContext(ctx, signer, contractAddress, nil, ERC721_ABI, MethodSafeTransferFrom721, args, price, nonce, limit)
 }
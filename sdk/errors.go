@@ -0,0 +1,122 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrTimeout is returned when receipts are not confirmed in tm.timeout seconds
+	ErrTimeout = errors.New("timeout")
+	// ErrReverted matches every *RevertError
+	ErrReverted = errors.New("execution reverted")
+	// ErrNonceTooLow is node's error of a nonce already used
+	ErrNonceTooLow = errors.New("nonce too low")
+	// ErrReplacementUnderpriced is node's error of replacing a pending tx without enough fee bump
+	ErrReplacementUnderpriced = errors.New("replacement transaction underpriced")
+	// ErrInsufficientFunds is node's error of a sender can't pay value + gas * price
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrInvalidChainID is returned when chain id of TransactionManager is not a number
+	ErrInvalidChainID = errors.New("invalid chain id")
+	// ErrUnexpectedReturn matches every *ReturnTypeError
+	ErrUnexpectedReturn = errors.New("unexpected returned data")
+	// ErrQueueFull is returned by TxQueue.TrySubmit when the sender has max pending jobs
+	ErrQueueFull = errors.New("queue is full")
+	// ErrQueueClosed is returned when submitting to a closed TxQueue
+	ErrQueueClosed = errors.New("queue is closed")
+)
+
+// Is makes errors.Is(err, ErrReverted) true for RevertError
+func (e *RevertError) Is(target error) bool {
+	return target == ErrReverted
+}
+
+// nodeErrors are messages of node errors matched to sentinels
+var nodeErrors = []struct {
+	msg string
+	err error
+}{
+	{"nonce too low", ErrNonceTooLow},
+	{"replacement transaction underpriced", ErrReplacementUnderpriced},
+	{"insufficient funds", ErrInsufficientFunds},
+}
+
+// nodeError is an error returned by node and matched to a sentinel, the message of node is kept
+type nodeError struct {
+	kind error
+	err  error
+}
+
+func (e *nodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *nodeError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *nodeError) Unwrap() error {
+	return e.err
+}
+
+// classifyNodeError wraps err of sending or estimating a tx, so that it matches a sentinel with errors.Is,
+// err is returned as it is when there is no matched sentinel
+func classifyNodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var ne *nodeError
+	if errors.As(err, &ne) {
+		return err
+	}
+	msg := err.Error()
+	for _, e := range nodeErrors {
+		if strings.Contains(msg, e.msg) {
+			return &nodeError{kind: e.err, err: err}
+		}
+	}
+	return err
+}
+
+// ArgError is an error of packing or unpacking the Index-th argument of a contract method
+type ArgError struct {
+	Method string // empty for constructor
+	Index  int
+	Type   string // abi type of the argument, it can be empty when the argument is not parsed
+	Err    error
+}
+
+func (e *ArgError) Error() string {
+	method := e.Method
+	if method == "" {
+		method = "constructor"
+	}
+	if e.Type == "" {
+		return fmt.Sprintf("%v argument %v: %v", method, e.Index, e.Err)
+	}
+	return fmt.Sprintf("%v argument %v (%v): %v", method, e.Index, e.Type, e.Err)
+}
+
+func (e *ArgError) Unwrap() error {
+	return e.Err
+}
+
+// ReturnTypeError is returned by helpers such as BalanceOf20 when a contract method returns unexpected data,
+// and by Unpack when it returns no data
+type ReturnTypeError struct {
+	Method string
+	Want   string        // type of the expected value
+	Got    []interface{} // unpacked values
+}
+
+func (e *ReturnTypeError) Error() string {
+	if len(e.Got) != 1 {
+		return fmt.Sprintf("%v() returned %v values, want one %v", e.Method, len(e.Got), e.Want)
+	}
+	return fmt.Sprintf("%v() returned %T, want %v", e.Method, e.Got[0], e.Want)
+}
+
+func (e *ReturnTypeError) Is(target error) bool {
+	return target == ErrUnexpectedReturn
+}
@@ -0,0 +1,140 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestClassifyNodeError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want error
+	}{
+		{errors.New("nonce too low"), ErrNonceTooLow},
+		{errors.New("replacement transaction underpriced"), ErrReplacementUnderpriced},
+		{errors.New("insufficient funds for gas * price + value"), ErrInsufficientFunds},
+		{errors.New("EstimateGas() error: insufficient funds for transfer"), ErrInsufficientFunds},
+	}
+	for _, c := range cases {
+		err := classifyNodeError(c.err)
+		if !errors.Is(err, c.want) || !errors.Is(err, c.err) || err.Error() != c.err.Error() {
+			t.Errorf("%v: %v", c.err, err)
+		}
+		if classifyNodeError(err) != err {
+			t.Errorf("%v is wrapped twice", c.err)
+		}
+	}
+	other := errors.New("already known")
+	if classifyNodeError(other) != other || classifyNodeError(nil) != nil {
+		t.Fatal("unknown errors should be returned as they are")
+	}
+
+	// errors of sending are classified
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(5)
+	tx, err := signer.SignTx(context.Background(), types.NewLondonSigner(chainID), testTxs(chainID)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := &flakySender{errs: []error{errors.New("insufficient funds for gas * price + value")}}
+	if err := sendSignedTx(context.Background(), nil, sender, tx); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("send: %v", err)
+	}
+}
+
+func TestRevertErrorIs(t *testing.T) {
+	var err error = &RevertError{Hash: "0x01", Reason: "not owner"}
+	if !errors.Is(err, ErrReverted) {
+		t.Fatal("RevertError should match ErrReverted")
+	}
+	var revertErr *RevertError
+	if !errors.As(err, &revertErr) || revertErr.Reason != "not owner" {
+		t.Fatalf("as: %v", revertErr)
+	}
+	if errors.Is(errors.New("execution reverted"), ErrReverted) {
+		t.Fatal("only RevertError matches ErrReverted")
+	}
+}
+
+func TestArgError(t *testing.T) {
+	cases := []struct {
+		method string
+		args   string
+		index  int
+	}{
+		{MethodTransfer, "address:0xd69cfc58b5a8b3b7866d2c2682ba971074a946a0;uint256:abc", 1},
+		{MethodTransfer, "address:0xd69cfc58b5a8b3b7866d2c2682ba971074a946a0;uint256", 1},
+		{MethodTransfer, "uint8:1", 0},
+		// type mismatch found by abi
+		{MethodTransfer, "uint256:3;uint256:5", 0},
+		{MethodTransferFrom, "address:0x01;address:0x02;address:0x03", 2},
+		// count mismatch
+		{MethodTransfer, "address:0x01", 1},
+	}
+	for _, c := range cases {
+		_, err := Pack(ERC20_ABI, c.method, c.args)
+		var argErr *ArgError
+		if !errors.As(err, &argErr) {
+			t.Errorf("%v(%v): expect ArgError, got %v", c.method, c.args, err)
+			continue
+		}
+		if argErr.Index != c.index || argErr.Method != c.method {
+			t.Errorf("%v(%v): %v", c.method, c.args, argErr)
+		}
+	}
+
+	pairABI := `[{"name":"pair","type":"function","inputs":[],"outputs":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}]}]`
+	// offset of b points out of data
+	data := append(common.LeftPadBytes([]byte{7}, 32), common.LeftPadBytes([]byte{0x40}, 32)...)
+	_, err := Unpack(pairABI, "pair", data)
+	var argErr *ArgError
+	if !errors.As(err, &argErr) || argErr.Index != 1 || argErr.Type != "string" {
+		t.Fatalf("unpack: %v", err)
+	}
+	// e.g. a call to an address without code
+	_, err = Unpack(ERC20_ABI, MethodBalanceOf, nil)
+	if !errors.Is(err, ErrUnexpectedReturn) || err.Error() != "balanceOf() returned 0 values, want one uint256" {
+		t.Fatalf("unpack empty data: %v", err)
+	}
+}
+
+func TestReturnTypeError(t *testing.T) {
+	var err error = &ReturnTypeError{Method: MethodDecimals, Want: "uint8", Got: []interface{}{big.NewInt(18)}}
+	if !errors.Is(err, ErrUnexpectedReturn) {
+		t.Fatal("ReturnTypeError should match ErrUnexpectedReturn")
+	}
+	if err.Error() != "decimals() returned *big.Int, want uint8" {
+		t.Fatal(err.Error())
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	tm := &TransactionManager{timeout: 60, waiter: newReceiptWaiter(newFakeChain(false), 10*time.Millisecond)}
+
+	// deadline of caller is not the timeout of tm
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := tm.WaitReceiptContext(ctx, "0x01", 1); err != context.DeadlineExceeded {
+		t.Fatalf("expect deadline exceeded, got %v", err)
+	}
+	if _, err := tm.WaitReceiptsContext(ctx, []string{"0x01"}, 1); err != context.DeadlineExceeded {
+		t.Fatalf("expect deadline exceeded, got %v", err)
+	}
+
+	tm.timeout = 1
+	if _, err := tm.WaitReceipt("0x01", 1); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expect ErrTimeout, got %v", err)
+	}
+	if _, _, err := tm.MinedReplacementSync("0x01"); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expect ErrTimeout, got %v", err)
+	}
+}
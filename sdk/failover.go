@@ -0,0 +1,535 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHealthInterval = 15 * time.Second
+	defaultMaxBlockLag    = 3
+	// timeout of a health check request
+	healthCheckTimeout = 5 * time.Second
+)
+
+// EndpointPolicy decides which healthy endpoint serves a read
+type EndpointPolicy int
+
+const (
+	// PriorityPolicy sends reads to the first healthy endpoint in config order
+	PriorityPolicy EndpointPolicy = iota
+	// RoundRobinPolicy spreads reads over healthy endpoints in turn
+	RoundRobinPolicy
+)
+
+// Endpoint is an http(s) JSON-RPC endpoint of FailoverConfig
+type Endpoint struct {
+	URL string
+	// Write makes transactions broadcast to this endpoint, all endpoints are written when none is set
+	Write bool
+}
+
+// FailoverConfig configures endpoints of DialFailover and NewFailover
+type FailoverConfig struct {
+	Endpoints []Endpoint // in priority order
+	Policy    EndpointPolicy
+	// endpoints are checked every HealthInterval, 0 for 15 seconds
+	HealthInterval time.Duration
+	// endpoints more than MaxBlockLag blocks behind the highest one are unhealthy, 0 for 3
+	MaxBlockLag uint64
+}
+
+// EndpointStatus is the result of the last health check or request of an endpoint
+type EndpointStatus struct {
+	URL         string
+	Healthy     bool
+	BlockNumber uint64
+	Syncing     bool
+	Err         error // why it's unhealthy
+}
+
+type endpoint struct {
+	Endpoint
+
+	mu     sync.Mutex
+	status EndpointStatus
+}
+
+func (e *endpoint) healthy() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.status.Healthy
+}
+
+// fail marks e unhealthy until next health check
+func (e *endpoint) fail(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.status.Healthy {
+		logrus.Warnf("endpoint %v is unhealthy: %v", e.URL, err)
+	}
+	e.status.Healthy = false
+	e.status.Err = err
+}
+
+// EndpointPool routes JSON-RPC requests of an rpc client over http to several endpoints:
+// reads go to a healthy endpoint and fail over to the next one on connection errors,
+// signed transactions are broadcast to all healthy write endpoints,
+// eth_sendTransaction goes to one write endpoint only, as each node would sign a different tx
+type EndpointPool struct {
+	endpoints   []*endpoint
+	policy      EndpointPolicy
+	interval    time.Duration
+	maxBlockLag uint64
+	transport   http.RoundTripper
+
+	next      uint32 // round robin counter
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+// DialFailover checks endpoints of cfg and returns an rpc client over them,
+// close the pool to stop health checks after closing the client
+func DialFailover(ctx context.Context, cfg FailoverConfig) (*rpc.Client, *EndpointPool, error) {
+	pool, err := newEndpointPool(ctx, cfg, http.DefaultTransport)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := rpc.DialHTTPWithClient(cfg.Endpoints[0].URL, &http.Client{Transport: pool})
+	if err != nil {
+		pool.Close()
+		return nil, nil, err
+	}
+	return client, pool, nil
+}
+
+func newEndpointPool(ctx context.Context, cfg FailoverConfig, transport http.RoundTripper) (*EndpointPool, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, fmt.Errorf("no endpoint")
+	}
+	p := &EndpointPool{
+		policy:      cfg.Policy,
+		interval:    cfg.HealthInterval,
+		maxBlockLag: cfg.MaxBlockLag,
+		transport:   transport,
+		quit:        make(chan struct{}),
+	}
+	if p.interval == 0 {
+		p.interval = defaultHealthInterval
+	}
+	if p.maxBlockLag == 0 {
+		p.maxBlockLag = defaultMaxBlockLag
+	}
+	for _, e := range cfg.Endpoints {
+		if !strings.HasPrefix(e.URL, "http://") && !strings.HasPrefix(e.URL, "https://") {
+			return nil, fmt.Errorf("endpoint %v is not http(s)", e.URL)
+		}
+		p.endpoints = append(p.endpoints, &endpoint{Endpoint: e, status: EndpointStatus{URL: e.URL}})
+	}
+
+	p.check(ctx)
+	go p.loop()
+	return p, nil
+}
+
+// Close stops health checks
+func (p *EndpointPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.quit)
+	})
+}
+
+// Status returns status of endpoints in config order
+func (p *EndpointPool) Status() []EndpointStatus {
+	ret := make([]EndpointStatus, len(p.endpoints))
+	for i, e := range p.endpoints {
+		e.mu.Lock()
+		ret[i] = e.status
+		e.mu.Unlock()
+	}
+	return ret
+}
+
+func (p *EndpointPool) loop() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.quit:
+			return
+		case <-ticker.C:
+			p.check(context.Background())
+		}
+	}
+}
+
+// check updates status of all endpoints by their block number and sync status
+func (p *EndpointPool) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	statuses := make([]EndpointStatus, len(p.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range p.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			statuses[i] = p.probe(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	var highest uint64
+	for _, s := range statuses {
+		if s.Err == nil && s.BlockNumber > highest {
+			highest = s.BlockNumber
+		}
+	}
+	for i, e := range p.endpoints {
+		s := statuses[i]
+		switch {
+		case s.Err != nil:
+		case s.Syncing:
+			s.Err = fmt.Errorf("syncing")
+		case s.BlockNumber+p.maxBlockLag < highest:
+			s.Err = fmt.Errorf("block %v is behind %v", s.BlockNumber, highest)
+		default:
+			s.Healthy = true
+		}
+
+		e.mu.Lock()
+		if e.status.Healthy != s.Healthy {
+			if s.Healthy {
+				logrus.Infof("endpoint %v is healthy at block %v", e.URL, s.BlockNumber)
+			} else {
+				logrus.Warnf("endpoint %v is unhealthy: %v", e.URL, s.Err)
+			}
+		}
+		e.status = s
+		e.mu.Unlock()
+	}
+}
+
+// probe gets block number and sync status of e
+func (p *EndpointPool) probe(ctx context.Context, e *endpoint) EndpointStatus {
+	status := EndpointStatus{URL: e.URL}
+
+	var number hexutil.Uint64
+	if err := p.call(ctx, e, &number, "eth_blockNumber"); err != nil {
+		status.Err = err
+		return status
+	}
+	status.BlockNumber = uint64(number)
+
+	// false, or an object when syncing
+	var syncing json.RawMessage
+	if err := p.call(ctx, e, &syncing, "eth_syncing"); err != nil {
+		status.Err = err
+		return status
+	}
+	status.Syncing = string(syncing) != "false"
+	return status
+}
+
+// call calls method without params on e
+func (p *EndpointPool) call(ctx context.Context, e *endpoint, result interface{}, method string) error {
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"%v","params":[]}`, method)
+	resp, err := p.send(ctx, e, []byte(body), nil)
+	if err != nil {
+		return err
+	}
+	var msg struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(resp.body, &msg); err != nil {
+		return fmt.Errorf("%v: invalid response: %s", method, err.Error())
+	}
+	if msg.Error != nil {
+		return fmt.Errorf("%v: %v", method, msg.Error.Message)
+	}
+	return json.Unmarshal(msg.Result, result)
+}
+
+// bufferedResponse is an http response with the body read
+type bufferedResponse struct {
+	*http.Response
+	body []byte
+}
+
+func (r *bufferedResponse) toHTTP() *http.Response {
+	r.Response.Body = ioutil.NopCloser(bytes.NewReader(r.body))
+	r.Response.ContentLength = int64(len(r.body))
+	return r.Response
+}
+
+// send posts body to e, server errors (5xx, 429) are returned as error so the request fails over
+func (p *EndpointPool) send(ctx context.Context, e *endpoint, body []byte, header http.Header) (*bufferedResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.ContentLength = int64(len(body))
+
+	resp, err := p.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("http status %v", resp.Status)
+	}
+	return &bufferedResponse{Response: resp, body: respBody}, nil
+}
+
+// readOrder returns endpoints to try for a read, healthy ones first by policy
+func (p *EndpointPool) readOrder() []*endpoint {
+	var healthy, unhealthy []*endpoint
+	for _, e := range p.endpoints {
+		if e.healthy() {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	if p.policy == RoundRobinPolicy && len(healthy) > 1 {
+		start := int(atomic.AddUint32(&p.next, 1)-1) % len(healthy)
+		healthy = append(healthy[start:], healthy[:start]...)
+	}
+	// unhealthy endpoints are the last resort
+	return append(healthy, unhealthy...)
+}
+
+// writeTargets returns endpoints a transaction is broadcast to
+func (p *EndpointPool) writeTargets() []*endpoint {
+	var writes []*endpoint
+	for _, e := range p.endpoints {
+		if e.Write {
+			writes = append(writes, e)
+		}
+	}
+	if len(writes) == 0 {
+		writes = p.endpoints
+	}
+	var healthy []*endpoint
+	for _, e := range writes {
+		if e.healthy() {
+			healthy = append(healthy, e)
+		}
+	}
+	if len(healthy) == 0 {
+		return writes
+	}
+	return healthy
+}
+
+// RoundTrip implements http.RoundTripper for rpc client
+func (p *EndpointPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	ctx := req.Context()
+
+	// a batch with eth_sendTransaction is sent once, broadcasting it would send several transactions
+	if isSingleRequest(body) {
+		return p.sendOnce(ctx, body, req.Header)
+	}
+	if isWriteRequest(body) {
+		return p.broadcast(ctx, body, req.Header)
+	}
+
+	var lastErr error
+	for _, e := range p.readOrder() {
+		resp, err := p.send(ctx, e, body, req.Header)
+		if err == nil {
+			return resp.toHTTP(), nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		e.fail(err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// sendOnce sends body to the first write target without failing over,
+// the request may have reached the endpoint when it fails
+func (p *EndpointPool) sendOnce(ctx context.Context, body []byte, header http.Header) (*http.Response, error) {
+	e := p.writeTargets()[0]
+	resp, err := p.send(ctx, e, body, header)
+	if err != nil {
+		if ctx.Err() == nil {
+			e.fail(err)
+		}
+		return nil, err
+	}
+	return resp.toHTTP(), nil
+}
+
+// broadcast sends body to all write targets, and returns the first response without JSON-RPC error in config order,
+// e.g. "already known" of other endpoints is ignored when one accepts the transaction
+func (p *EndpointPool) broadcast(ctx context.Context, body []byte, header http.Header) (*http.Response, error) {
+	targets := p.writeTargets()
+	resps := make([]*bufferedResponse, len(targets))
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, e := range targets {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			resps[i], errs[i] = p.send(ctx, e, body, header)
+			if errs[i] != nil && ctx.Err() == nil {
+				e.fail(errs[i])
+			}
+		}(i, e)
+	}
+	wg.Wait()
+
+	var first *bufferedResponse
+	for i, resp := range resps {
+		if resp == nil {
+			continue
+		}
+		if !hasRPCError(resp.body) {
+			return resp.toHTTP(), nil
+		}
+		if first == nil {
+			first = resps[i]
+		}
+	}
+	if first != nil {
+		return first.toHTTP(), nil
+	}
+	return nil, errs[0]
+}
+
+// NewFailover makes a TransactionManager over several endpoints, arguments except cfg are the same as New
+// subscriptions are not available over http, so *Sync helpers poll receipts every interval
+func NewFailover(cfg FailoverConfig, gasPrice *big.Int, gasLimit, timeout, interval uint64) (*TransactionManager, error) {
+	return NewFailoverContext(context.Background(), cfg, gasPrice, gasLimit, timeout, interval)
+}
+
+// NewFailoverContext is like NewFailover but with ctx for cancellation and deadline
+func NewFailoverContext(ctx context.Context, cfg FailoverConfig, gasPrice *big.Int, gasLimit, timeout, interval uint64) (*TransactionManager, error) {
+	rpcClient, pool, err := DialFailover(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	tm := &TransactionManager{
+		rpcURL:       cfg.Endpoints[0].URL,
+		gasLimit:     gasLimit,
+		timeout:      timeout,
+		interval:     interval,
+		eip155:       true,
+		endpointPool: pool,
+	}
+	if err := tm.init(ctx, rpcClient, gasPrice); err != nil {
+		tm.Close()
+		return nil, err
+	}
+	return tm, nil
+}
+
+// Endpoints returns status of endpoints of TransactionManager made by NewFailover, nil for New
+func (tm *TransactionManager) Endpoints() []EndpointStatus {
+	if tm.endpointPool == nil {
+		return nil
+	}
+	return tm.endpointPool.Status()
+}
+
+// writeMethods are broadcast to write endpoints, a signed transaction is the same tx on every node
+var writeMethods = map[string]bool{
+	"eth_sendRawTransaction": true,
+}
+
+// singleMethods are sent to exactly one write endpoint,
+// eth_sendTransaction is signed by the node, broadcasting it would send a different tx from every node
+var singleMethods = map[string]bool{
+	"eth_sendTransaction": true,
+}
+
+// rpcMessage is the part of JSON-RPC request or response used by EndpointPool
+type rpcMessage struct {
+	Method string          `json:"method"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// decodeRPCMessages decodes a JSON-RPC message or batch
+func decodeRPCMessages(body []byte) ([]rpcMessage, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var msgs []rpcMessage
+		err := json.Unmarshal(body, &msgs)
+		return msgs, err
+	}
+	var msg rpcMessage
+	err := json.Unmarshal(body, &msg)
+	return []rpcMessage{msg}, err
+}
+
+// isWriteRequest reports whether body sends signed transactions
+func isWriteRequest(body []byte) bool {
+	return hasMethod(body, writeMethods)
+}
+
+// isSingleRequest reports whether body has a request sent to one endpoint only
+func isSingleRequest(body []byte) bool {
+	return hasMethod(body, singleMethods)
+}
+
+// hasMethod reports whether body has a request of any of methods
+func hasMethod(body []byte, methods map[string]bool) bool {
+	msgs, err := decodeRPCMessages(body)
+	if err != nil {
+		return false
+	}
+	for _, msg := range msgs {
+		if methods[msg.Method] {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRPCError reports whether response body has an error
+func hasRPCError(body []byte) bool {
+	msgs, err := decodeRPCMessages(body)
+	if err != nil {
+		return true
+	}
+	for _, msg := range msgs {
+		if len(msg.Error) > 0 && string(msg.Error) != "null" {
+			return true
+		}
+	}
+	return false
+}
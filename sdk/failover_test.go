@@ -0,0 +1,239 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newTestNode starts a testBackend at block
+func newTestNode(t *testing.T, block uint64) *testBackend {
+	b := newTestBackend(t)
+	b.set(func(b *testBackend) { b.block = block })
+	return b
+}
+
+// signedRawTx returns a signed transfer of chain 5 with nonce
+func signedRawTx(t *testing.T, nonce uint64) string {
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := types.NewTransaction(nonce, common.HexToAddress(testAddress(1)), big.NewInt(1), 21000, big.NewInt(1e9), nil)
+	signed, err := signer.SignTx(context.Background(), types.NewEIP155Signer(big.NewInt(5)), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hexutil.Encode(raw)
+}
+
+func dialTestFailover(t *testing.T, cfg FailoverConfig) (*rpc.Client, *EndpointPool, func()) {
+	if cfg.HealthInterval == 0 {
+		cfg.HealthInterval = time.Hour
+	}
+	client, pool, err := DialFailover(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, pool, func() {
+		client.Close()
+		pool.Close()
+	}
+}
+
+func TestFailoverPriority(t *testing.T) {
+	a, b := newTestNode(t, 100), newTestNode(t, 100)
+	defer b.close()
+	client, pool, closeFn := dialTestFailover(t, FailoverConfig{Endpoints: []Endpoint{{URL: a.http.URL}, {URL: b.http.URL}}})
+	defer closeFn()
+
+	var chainID hexutil.Big
+	for i := 0; i < 3; i++ {
+		if err := client.Call(&chainID, "eth_chainId"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.count("eth_chainId") != 3 || b.count("eth_chainId") != 0 {
+		t.Fatalf("reads: %v %v", a.count("eth_chainId"), b.count("eth_chainId"))
+	}
+
+	// fail over when the first endpoint is down
+	a.close()
+	if err := client.Call(&chainID, "eth_chainId"); err != nil {
+		t.Fatal(err)
+	}
+	if b.count("eth_chainId") != 1 {
+		t.Fatalf("reads of second endpoint: %v", b.count("eth_chainId"))
+	}
+	status := pool.Status()
+	if status[0].Healthy || status[0].Err == nil || !status[1].Healthy {
+		t.Fatalf("status: %+v", status)
+	}
+}
+
+func TestFailoverHealthCheck(t *testing.T) {
+	a, b, c := newTestNode(t, 100), newTestNode(t, 100), newTestNode(t, 90)
+	defer a.close()
+	defer b.close()
+	defer c.close()
+	b.set(func(b *testBackend) { b.syncing = true })
+
+	client, pool, closeFn := dialTestFailover(t, FailoverConfig{
+		Endpoints:   []Endpoint{{URL: c.http.URL}, {URL: b.http.URL}, {URL: a.http.URL}},
+		MaxBlockLag: 5,
+	})
+	defer closeFn()
+	status := pool.Status()
+	if status[0].Healthy || status[1].Healthy || !status[2].Healthy {
+		t.Fatalf("status: %+v", status)
+	}
+	if !status[1].Syncing || status[0].BlockNumber != 90 {
+		t.Fatalf("status: %+v", status)
+	}
+
+	var chainID hexutil.Big
+	if err := client.Call(&chainID, "eth_chainId"); err != nil {
+		t.Fatal(err)
+	}
+	if a.count("eth_chainId") != 1 {
+		t.Fatal("read should go to the healthy endpoint")
+	}
+
+	// caught up
+	c.set(func(c *testBackend) { c.block = 98 })
+	pool.check(context.Background())
+	if !pool.Status()[0].Healthy {
+		t.Fatalf("status: %+v", pool.Status()[0])
+	}
+}
+
+func TestFailoverRoundRobin(t *testing.T) {
+	nodes := []*testBackend{newTestNode(t, 1), newTestNode(t, 1), newTestNode(t, 1)}
+	var endpoints []Endpoint
+	for _, n := range nodes {
+		defer n.close()
+		endpoints = append(endpoints, Endpoint{URL: n.http.URL})
+	}
+	client, _, closeFn := dialTestFailover(t, FailoverConfig{Endpoints: endpoints, Policy: RoundRobinPolicy})
+	defer closeFn()
+
+	var chainID hexutil.Big
+	for i := 0; i < 6; i++ {
+		if err := client.Call(&chainID, "eth_chainId"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, n := range nodes {
+		if n.count("eth_chainId") != 2 {
+			t.Fatalf("reads of endpoint %v: %v", i, n.count("eth_chainId"))
+		}
+	}
+}
+
+func TestFailoverBroadcast(t *testing.T) {
+	a, b, c := newTestNode(t, 1), newTestNode(t, 1), newTestNode(t, 1)
+	defer a.close()
+	defer b.close()
+	defer c.close()
+	a.set(func(a *testBackend) {
+		a.sendErr = func(tx *types.Transaction) error { return errors.New("already known") }
+	})
+
+	client, _, closeFn := dialTestFailover(t, FailoverConfig{
+		Endpoints: []Endpoint{{URL: a.http.URL, Write: true}, {URL: b.http.URL, Write: true}, {URL: c.http.URL}},
+	})
+	defer closeFn()
+
+	raw := signedRawTx(t, 0)
+	var hash common.Hash
+	if err := client.Call(&hash, "eth_sendRawTransaction", raw); err != nil {
+		t.Fatalf("expect response of the accepting endpoint: %v", err)
+	}
+	if hash != b.lastSent(t).Hash() {
+		t.Fatalf("hash %v", hash.String())
+	}
+	if a.count("eth_sendRawTransaction") != 1 || b.count("eth_sendRawTransaction") != 1 || c.count("eth_sendRawTransaction") != 0 {
+		t.Fatalf("sends: %v %v %v", a.count("eth_sendRawTransaction"), b.count("eth_sendRawTransaction"), c.count("eth_sendRawTransaction"))
+	}
+
+	// all rejected
+	b.set(func(b *testBackend) {
+		b.sendErr = func(tx *types.Transaction) error { return errors.New("nonce too low") }
+	})
+	if err := client.Call(&hash, "eth_sendRawTransaction", raw); err == nil || err.Error() != "already known" {
+		t.Fatalf("expect error of the first endpoint: %v", err)
+	}
+
+	// tx signed by node is sent to one write endpoint only
+	if err := client.Call(&hash, "eth_sendTransaction", map[string]string{"from": testAddress(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if a.count("eth_sendTransaction") != 1 || b.count("eth_sendTransaction") != 0 || c.count("eth_sendTransaction") != 0 {
+		t.Fatalf("sends: %v %v %v", a.count("eth_sendTransaction"), b.count("eth_sendTransaction"), c.count("eth_sendTransaction"))
+	}
+	// and it doesn't fail over, the tx may have been sent
+	a.close()
+	if err := client.Call(&hash, "eth_sendTransaction", map[string]string{"from": testAddress(1)}); err == nil {
+		t.Fatal("expect error of the closed endpoint")
+	}
+	if b.count("eth_sendTransaction") != 0 {
+		t.Fatal("eth_sendTransaction is failed over")
+	}
+}
+
+func TestIsWriteRequest(t *testing.T) {
+	cases := []struct {
+		body string
+		want bool
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x01"]}`, true},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}`, false},
+		{`[{"id":1,"method":"eth_blockNumber"},{"id":2,"method":"eth_sendRawTransaction"}]`, true},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_sendTransaction","params":[]}`, false},
+		{`[{"id":1,"method":"eth_blockNumber"}]`, false},
+		{`not json`, false},
+	}
+	for _, c := range cases {
+		if got := isWriteRequest([]byte(c.body)); got != c.want {
+			t.Errorf("%v: got %v", c.body, got)
+		}
+	}
+	if !isSingleRequest([]byte(`[{"id":1,"method":"eth_blockNumber"},{"id":2,"method":"eth_sendTransaction"}]`)) {
+		t.Error("eth_sendTransaction should be sent once")
+	}
+
+	if hasRPCError([]byte(`{"id":1,"result":"0x1"}`)) || hasRPCError([]byte(`{"id":1,"result":"0x1","error":null}`)) {
+		t.Error("no error expected")
+	}
+	if !hasRPCError([]byte(`[{"id":1,"result":"0x1"},{"id":2,"error":{"code":-32000,"message":"x"}}]`)) {
+		t.Error("error expected")
+	}
+}
+
+func TestNewFailoverContext(t *testing.T) {
+	a := newTestNode(t, 1)
+	defer a.close()
+	tm, err := NewFailoverContext(context.Background(), FailoverConfig{Endpoints: []Endpoint{{URL: a.http.URL}}}, big.NewInt(1e9), 21000, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tm.Close()
+	if tm.chainID != "5" || len(tm.Endpoints()) != 1 {
+		t.Fatalf("chain id %v endpoints %+v", tm.chainID, tm.Endpoints())
+	}
+
+	if _, err := NewFailover(FailoverConfig{Endpoints: []Endpoint{{URL: "ws://localhost:8546"}}}, big.NewInt(1e9), 21000, 0, 0); err == nil {
+		t.Fatal("expect error of websocket endpoint")
+	}
+}
@@ -0,0 +1,99 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	defaultGasMultiplier = 1.2
+)
+
+// EnableGasEstimation makes SendTx and its callers estimate gas limit when it's passed as 0,
+// the estimated gas is scaled by multiplier and clamped to cap
+// multiplier less than 1 uses default multiplier 1.2, set cap to 0 to use gas limit of latest block
+func (tm *TransactionManager) EnableGasEstimation(multiplier float64, cap uint64) {
+	if multiplier < 1 {
+		multiplier = defaultGasMultiplier
+	}
+	tm.estimateGas = true
+	tm.gasMultiplier = multiplier
+	tm.gasLimitCap = cap
+}
+
+// DisableGasEstimation makes SendTx use tm.gasLimit when gas limit is passed as 0,
+// it's also used by TransferEthWithData when data is not empty, so it must cover the call of data
+func (tm *TransactionManager) DisableGasEstimation() {
+	tm.estimateGas = false
+}
+
+// transferGasLimit returns gas limit of eth transfer: 0 to estimate it or use tm.gasLimit when there is data,
+// otherwise transferEthLimit
+func (tm *TransactionManager) transferGasLimit(data []byte) uint64 {
+	if tm.estimateGas || len(data) > 0 {
+		return 0
+	}
+	return transferEthLimit
+}
+
+// EstimateGasLimit estimates gas limit of tx from fromAddr to toAddr, scaled and clamped as SendTx does
+// set toAddr to empty string for contract creation
+// error is *RevertError when the tx would revert
+func (tm *TransactionManager) EstimateGasLimit(fromAddr string, toAddr string, value *big.Int, data []byte) (uint64, error) {
+	return tm.EstimateGasLimitContext(context.Background(), fromAddr, toAddr, value, data)
+}
+
+// EstimateGasLimitContext is like EstimateGasLimit but with ctx for cancellation and deadline
+func (tm *TransactionManager) EstimateGasLimitContext(ctx context.Context, fromAddr string, toAddr string, value *big.Int, data []byte) (uint64, error) {
+	var to *common.Address
+	if toAddr != "" {
+		toAddress := common.HexToAddress(toAddr)
+		to = &toAddress
+	}
+	return tm.estimateGasLimit(ctx, common.HexToAddress(fromAddr), to, value, data, nil, "")
+}
+
+func (tm *TransactionManager) estimateGasLimit(ctx context.Context, from common.Address, to *common.Address, value *big.Int, data []byte, accessList types.AccessList, abiStr string) (uint64, error) {
+	msg := ethereum.CallMsg{
+		From:       from,
+		To:         to,
+		Value:      value,
+		Data:       data,
+		AccessList: accessList,
+	}
+	// ethclient drops access list of msg, it changes the gas used
+	gas, err := tm.estimateCallGas(ctx, msg)
+	if err != nil {
+		if revertErr := newRevertError(err, abiStr); revertErr != nil {
+			return 0, revertErr
+		}
+		return 0, classifyNodeError(fmt.Errorf("EstimateGas() error: %w", err))
+	}
+
+	multiplier := tm.gasMultiplier
+	if multiplier == 0 {
+		multiplier = defaultGasMultiplier
+	}
+	limit := uint64(float64(gas) * multiplier)
+
+	limitCap := tm.gasLimitCap
+	if limitCap == 0 {
+		head, err := tm.Client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return 0, fmt.Errorf("HeaderByNumber() error: %s", err.Error())
+		}
+		limitCap = head.GasLimit
+	}
+	if gas > limitCap {
+		return 0, fmt.Errorf("estimated gas %v exceeds gas limit cap %v", gas, limitCap)
+	}
+	if limit > limitCap {
+		limit = limitCap
+	}
+	return limit, nil
+}
@@ -0,0 +1,287 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// node's suggestion and fee history are cached for this long by default
+	defaultGasPriceRefresh = time.Minute
+	// most blocks eth_feeHistory of geth returns
+	maxFeeHistoryBlocks = 1024
+)
+
+// GasPriceStrategy decides gas price of legacy transactions and max priority fee (tip) of EIP-1559 transactions
+// GasTipCap returns nil tip to leave the tip to node's suggestion
+type GasPriceStrategy interface {
+	GasPrice(ctx context.Context) (*big.Int, error)
+	GasTipCap(ctx context.Context) (*big.Int, error)
+}
+
+// FixedGasPrice always returns the same gas price of legacy transactions and the same tip,
+// the tip is suggested by node when Tip is nil, a legacy gas price is not a priority fee
+type FixedGasPrice struct {
+	Price *big.Int
+	Tip   *big.Int
+}
+
+// NewFixedGasPrice makes FixedGasPrice, set tip to nil to use tip suggested by node
+// price must not be nil, GasPrice returns error of it
+func NewFixedGasPrice(price *big.Int, tip *big.Int) *FixedGasPrice {
+	return &FixedGasPrice{Price: price, Tip: tip}
+}
+
+func (f *FixedGasPrice) GasPrice(ctx context.Context) (*big.Int, error) {
+	if f.Price == nil {
+		return nil, fmt.Errorf("price of FixedGasPrice is nil")
+	}
+	return new(big.Int).Set(f.Price), nil
+}
+
+func (f *FixedGasPrice) GasTipCap(ctx context.Context) (*big.Int, error) {
+	if f.Tip == nil {
+		return nil, nil
+	}
+	return new(big.Int).Set(f.Tip), nil
+}
+
+// GasSuggester suggests gas price and tip, *ethclient.Client implements it
+type GasSuggester interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+}
+
+// cachedPrice is a price refreshed when it's older than refresh
+type cachedPrice struct {
+	mu      sync.Mutex
+	value   *big.Int
+	updated time.Time
+}
+
+func (c *cachedPrice) get(ctx context.Context, refresh time.Duration, fetch func(ctx context.Context) (*big.Int, error)) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.value == nil || time.Since(c.updated) >= refresh {
+		value, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.value = value
+		c.updated = time.Now()
+	}
+	return new(big.Int).Set(c.value), nil
+}
+
+// NodeGasPrice uses gas price and tip suggested by node, refreshed every interval
+type NodeGasPrice struct {
+	suggester GasSuggester
+	refresh   time.Duration
+	price     cachedPrice
+	tip       cachedPrice
+}
+
+// NewNodeGasPrice makes NodeGasPrice, set refresh to 0 to use default 1 minute
+func NewNodeGasPrice(suggester GasSuggester, refresh time.Duration) *NodeGasPrice {
+	if refresh == 0 {
+		refresh = defaultGasPriceRefresh
+	}
+	return &NodeGasPrice{suggester: suggester, refresh: refresh}
+}
+
+func (n *NodeGasPrice) GasPrice(ctx context.Context) (*big.Int, error) {
+	return n.price.get(ctx, n.refresh, func(ctx context.Context) (*big.Int, error) {
+		price, err := n.suggester.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("SuggestGasPrice() error: %s", err.Error())
+		}
+		return price, nil
+	})
+}
+
+func (n *NodeGasPrice) GasTipCap(ctx context.Context) (*big.Int, error) {
+	return n.tip.get(ctx, n.refresh, func(ctx context.Context) (*big.Int, error) {
+		tip, err := n.suggester.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("SuggestGasTipCap() error: %s", err.Error())
+		}
+		return tip, nil
+	})
+}
+
+// RPCCaller calls json rpc methods, *rpc.Client implements it
+type RPCCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// FeeHistoryGasPrice uses the percentile of priority fees paid in recent blocks from eth_feeHistory as tip,
+// and 2 * base fee of the next block plus the tip as gas price, so a legacy tx still pays when base fee rises
+type FeeHistoryGasPrice struct {
+	caller     RPCCaller
+	blocks     int
+	percentile float64
+	refresh    time.Duration
+
+	mu      sync.Mutex
+	price   *big.Int
+	tip     *big.Int
+	updated time.Time
+}
+
+// NewFeeHistoryGasPrice makes FeeHistoryGasPrice over the last blocks in [1, 1024] with percentile in [0, 100],
+// set refresh to 0 to use default 1 minute
+func NewFeeHistoryGasPrice(caller RPCCaller, blocks int, percentile float64, refresh time.Duration) (*FeeHistoryGasPrice, error) {
+	if blocks < 1 || blocks > maxFeeHistoryBlocks {
+		return nil, fmt.Errorf("blocks of fee history must be in [1, %v], got %v", maxFeeHistoryBlocks, blocks)
+	}
+	if percentile < 0 || percentile > 100 {
+		return nil, fmt.Errorf("percentile of fee history must be in [0, 100], got %v", percentile)
+	}
+	if refresh == 0 {
+		refresh = defaultGasPriceRefresh
+	}
+	return &FeeHistoryGasPrice{
+		caller:     caller,
+		blocks:     blocks,
+		percentile: percentile,
+		refresh:    refresh,
+	}, nil
+}
+
+type feeHistory struct {
+	OldestBlock   *hexutil.Big     `json:"oldestBlock"`
+	BaseFeePerGas []*hexutil.Big   `json:"baseFeePerGas"`
+	GasUsedRatio  []float64        `json:"gasUsedRatio"`
+	Reward        [][]*hexutil.Big `json:"reward"`
+}
+
+func (f *FeeHistoryGasPrice) update(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.price != nil && time.Since(f.updated) < f.refresh {
+		return nil
+	}
+	var history feeHistory
+	err := f.caller.CallContext(ctx, &history, "eth_feeHistory", hexutil.Uint(f.blocks), "latest", []float64{f.percentile})
+	if err != nil {
+		return fmt.Errorf("eth_feeHistory error: %s", err.Error())
+	}
+	if len(history.BaseFeePerGas) == 0 {
+		return fmt.Errorf("eth_feeHistory returned no base fee")
+	}
+
+	var rewards []*big.Int
+	for _, reward := range history.Reward {
+		if len(reward) > 0 && reward[0] != nil {
+			rewards = append(rewards, reward[0].ToInt())
+		}
+	}
+	tip := new(big.Int)
+	if len(rewards) > 0 {
+		// median of the percentile rewards of blocks
+		sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+		tip.Set(rewards[len(rewards)/2])
+	}
+	// the last base fee is the one of the next block
+	baseFee := history.BaseFeePerGas[len(history.BaseFeePerGas)-1].ToInt()
+
+	f.tip = tip
+	f.price = new(big.Int).Mul(baseFee, big.NewInt(2))
+	f.price.Add(f.price, tip)
+	f.updated = time.Now()
+	return nil
+}
+
+func (f *FeeHistoryGasPrice) GasPrice(ctx context.Context) (*big.Int, error) {
+	if err := f.update(ctx); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return new(big.Int).Set(f.price), nil
+}
+
+func (f *FeeHistoryGasPrice) GasTipCap(ctx context.Context) (*big.Int, error) {
+	if err := f.update(ctx); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return new(big.Int).Set(f.tip), nil
+}
+
+// WaitGasPriceBelow wraps a strategy for non-urgent transactions,
+// it blocks until gas price of the wrapped strategy is not greater than Max, checking every Poll
+type WaitGasPriceBelow struct {
+	Strategy GasPriceStrategy
+	Max      *big.Int
+	Poll     time.Duration
+}
+
+// NewWaitGasPriceBelow makes WaitGasPriceBelow, set poll to 0 to check every 15 seconds
+func NewWaitGasPriceBelow(strategy GasPriceStrategy, max *big.Int, poll time.Duration) *WaitGasPriceBelow {
+	if poll == 0 {
+		poll = 15 * time.Second
+	}
+	return &WaitGasPriceBelow{Strategy: strategy, Max: max, Poll: poll}
+}
+
+// wait returns gas price once it's not greater than w.Max
+func (w *WaitGasPriceBelow) wait(ctx context.Context) (*big.Int, error) {
+	ticker := time.NewTicker(w.Poll)
+	defer ticker.Stop()
+
+	for {
+		price, err := w.Strategy.GasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if price.Cmp(w.Max) <= 0 {
+			return price, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *WaitGasPriceBelow) GasPrice(ctx context.Context) (*big.Int, error) {
+	return w.wait(ctx)
+}
+
+func (w *WaitGasPriceBelow) GasTipCap(ctx context.Context) (*big.Int, error) {
+	if _, err := w.wait(ctx); err != nil {
+		return nil, err
+	}
+	return w.Strategy.GasTipCap(ctx)
+}
+
+// SetGasPriceStrategy sets strategy of gas price and tip used when they are passed as nil
+func (tm *TransactionManager) SetGasPriceStrategy(strategy GasPriceStrategy) {
+	tm.gasPriceStrategy = strategy
+}
+
+// WaitGasPriceBelow blocks until gas price of tm's strategy is not greater than max or ctx is done,
+// it checks every tm.interval seconds
+func (tm *TransactionManager) WaitGasPriceBelow(ctx context.Context, max *big.Int) (*big.Int, error) {
+	w := NewWaitGasPriceBelow(tm.gasPriceStrategy, max, time.Second*time.Duration(tm.interval))
+	return w.wait(ctx)
+}
+
+// legacyGasPrice returns gasPrice, or gas price of tm's strategy when it's nil
+func (tm *TransactionManager) legacyGasPrice(ctx context.Context, gasPrice *big.Int) (*big.Int, error) {
+	if gasPrice != nil {
+		return new(big.Int).Set(gasPrice), nil
+	}
+	return tm.gasPriceStrategy.GasPrice(ctx)
+}
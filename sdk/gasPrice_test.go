@@ -0,0 +1,140 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeSuggester struct {
+	calls int
+	price int64
+}
+
+func (f *fakeSuggester) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	f.calls++
+	return big.NewInt(atomic.LoadInt64(&f.price)), nil
+}
+
+func (f *fakeSuggester) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func TestNodeGasPriceRefresh(t *testing.T) {
+	s := &fakeSuggester{price: 100}
+	n := NewNodeGasPrice(s, 50*time.Millisecond)
+	ctx := context.Background()
+
+	if price, _ := n.GasPrice(ctx); price.Int64() != 100 {
+		t.Fatalf("price: %v", price)
+	}
+	s.price = 200
+	if price, _ := n.GasPrice(ctx); price.Int64() != 100 || s.calls != 1 {
+		t.Fatalf("cached price: %v, calls %v", price, s.calls)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if price, _ := n.GasPrice(ctx); price.Int64() != 200 || s.calls != 2 {
+		t.Fatalf("refreshed price: %v, calls %v", price, s.calls)
+	}
+}
+
+func TestFixedGasPrice(t *testing.T) {
+	ctx := context.Background()
+	f := NewFixedGasPrice(big.NewInt(100), nil)
+	if price, err := f.GasPrice(ctx); err != nil || price.Int64() != 100 {
+		t.Fatalf("price: %v %v", price, err)
+	}
+	if tip, err := f.GasTipCap(ctx); err != nil || tip != nil {
+		t.Fatalf("tip: %v %v", tip, err)
+	}
+	// nil price is an error, not a panic
+	if _, err := NewFixedGasPrice(nil, big.NewInt(1)).GasPrice(ctx); err == nil {
+		t.Fatal("expect error of nil price")
+	}
+	if _, err := (&FixedGasPrice{}).GasPrice(ctx); err == nil {
+		t.Fatal("expect error of nil price")
+	}
+}
+
+type fakeCaller struct {
+	result string
+}
+
+func (f *fakeCaller) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return json.Unmarshal([]byte(f.result), result)
+}
+
+func TestFeeHistoryGasPrice(t *testing.T) {
+	caller := &fakeCaller{result: `{
+		"oldestBlock": "0x10",
+		"baseFeePerGas": ["0x64", "0x6e", "0x78", "0xc8"],
+		"gasUsedRatio": [0.5, 0.9, 0.7],
+		"reward": [["0x5"], ["0x1"], ["0x3"]]
+	}`}
+	f, err := NewFeeHistoryGasPrice(caller, 3, 50, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tip, err := f.GasTipCap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tip.Int64() != 3 {
+		t.Fatalf("tip: %v", tip)
+	}
+	// 2 * base fee of next block 0xc8 plus tip
+	price, err := f.GasPrice(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.Int64() != 403 {
+		t.Fatalf("price: %v", price)
+	}
+
+	for _, c := range []struct {
+		blocks     int
+		percentile float64
+	}{
+		{0, 50},
+		{1025, 50},
+		{10, -1},
+		{10, 101},
+	} {
+		if _, err := NewFeeHistoryGasPrice(caller, c.blocks, c.percentile, 0); err == nil {
+			t.Fatalf("expect error of blocks %v percentile %v", c.blocks, c.percentile)
+		}
+	}
+}
+
+func TestWaitGasPriceBelow(t *testing.T) {
+	s := &fakeSuggester{price: 300}
+	w := NewWaitGasPriceBelow(NewNodeGasPrice(s, time.Millisecond), big.NewInt(150), 5*time.Millisecond)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		atomic.StoreInt64(&s.price, 120)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	price, err := w.GasPrice(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.Int64() != 120 {
+		t.Fatalf("price: %v", price)
+	}
+
+	atomic.StoreInt64(&s.price, 1000)
+	// let cached price expire
+	time.Sleep(5 * time.Millisecond)
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := w.GasPrice(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expect deadline exceeded, got %v", err)
+	}
+}
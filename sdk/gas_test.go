@@ -0,0 +1,102 @@
+package sdk
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestEstimateGasLimit(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.close()
+	tm, closeFn := backend.newTM(t)
+	defer closeFn()
+
+	tests := []struct {
+		name       string
+		multiplier float64
+		cap        uint64
+		estimate   uint64
+		err        error
+		limit      uint64
+		reason     string // expected reason of RevertError
+		is         error  // expected sentinel of error
+		fail       bool
+	}{
+		{name: "multiplier", multiplier: 1.5, estimate: 50000, limit: 75000},
+		{name: "default multiplier", multiplier: 0.5, estimate: 50000, limit: 60000},
+		{name: "clamped to cap", multiplier: 2, cap: 80000, estimate: 50000, limit: 80000},
+		{name: "clamped to block gas limit", multiplier: 2, estimate: 20e6, limit: 30e6},
+		{name: "estimate above cap", multiplier: 1.2, cap: 80000, estimate: 100000, fail: true},
+		{name: "estimate above block gas limit", multiplier: 1.2, estimate: 40e6, fail: true},
+		{name: "revert reason", multiplier: 1.2, err: testRevert{encodeRevert(t, errorSelector, "string", "not owner")}, reason: "not owner"},
+		{name: "revert without data", multiplier: 1.2, err: errors.New("execution reverted"), is: ErrReverted},
+		{name: "node error", multiplier: 1.2, err: errors.New("insufficient funds for transfer"), is: ErrInsufficientFunds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend.set(func(b *testBackend) {
+				b.estimate = func(args testCallArgs) (uint64, error) {
+					return tt.estimate, tt.err
+				}
+			})
+			tm.EnableGasEstimation(tt.multiplier, tt.cap)
+			limit, err := tm.EstimateGasLimit(testAddress(1), testAddress(2), big.NewInt(1), []byte{1})
+
+			var revertErr *RevertError
+			switch {
+			case tt.reason != "":
+				if !errors.As(err, &revertErr) || revertErr.Reason != tt.reason {
+					t.Fatalf("expect revert %q, got %v", tt.reason, err)
+				}
+			case tt.is != nil:
+				if !errors.Is(err, tt.is) {
+					t.Fatalf("expect %v, got %v", tt.is, err)
+				}
+			case tt.fail:
+				if err == nil {
+					t.Fatalf("expect error, got limit %v", limit)
+				}
+			default:
+				if err != nil || limit != tt.limit {
+					t.Fatalf("limit %v %v, want %v", limit, err, tt.limit)
+				}
+			}
+		})
+	}
+}
+
+func TestEstimateGasAccessList(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.close()
+	tm, closeFn := backend.newTM(t)
+	defer closeFn()
+
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var estimated *types.AccessList
+	backend.set(func(b *testBackend) {
+		b.estimate = func(args testCallArgs) (uint64, error) {
+			estimated = args.AccessList
+			return 30000, nil
+		}
+	})
+	tm.EnableGasEstimation(1, 0)
+	accessList := types.AccessList{{Address: common.HexToAddress(testAddress(3)), StorageKeys: []common.Hash{}}}
+	if _, err := tm.SendTxWithAccessList(signer, testAddress(2), big.NewInt(1), nil, accessList, nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	backend.set(func(b *testBackend) {
+		if estimated == nil || len(*estimated) != 1 || (*estimated)[0].Address != accessList[0].Address {
+			t.Fatalf("access list is not estimated with: %v", estimated)
+		}
+	})
+	if gas := backend.lastSent(t).Gas(); gas != 30000 {
+		t.Fatalf("gas limit %v", gas)
+	}
+}
@@ -0,0 +1,154 @@
+package sdk
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// DefaultDerivationPath is the BIP-44 path prefix of ethereum accounts, account i is at DefaultDerivationPath/i
+	DefaultDerivationPath = "m/44'/60'/0'/0"
+
+	// child indexes from it are hardened
+	hardenedOffset = 0x80000000
+)
+
+// NewMnemonic makes a BIP-39 english mnemonic of bits entropy,
+// bits is one of 128, 160, 192, 224, 256 for 12, 15, 18, 21, 24 words
+func NewMnemonic(bits int) (string, error) {
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// ValidateMnemonic checks words and checksum of mnemonic
+func ValidateMnemonic(mnemonic string) error {
+	_, err := bip39.EntropyFromMnemonic(normalizeMnemonic(mnemonic))
+	if err != nil {
+		return fmt.Errorf("invalid mnemonic: %s", err.Error())
+	}
+	return nil
+}
+
+// MnemonicToSeed validates mnemonic and derives BIP-39 seed of it with optional passphrase
+func MnemonicToSeed(mnemonic string, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	return bip39.NewSeed(normalizeMnemonic(mnemonic), passphrase), nil
+}
+
+func normalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(mnemonic), " ")
+}
+
+// HDWallet derives BIP-32 keys from a seed
+type HDWallet struct {
+	master *extendedKey
+}
+
+// extendedKey is a BIP-32 private key with its chain code
+type extendedKey struct {
+	key       *big.Int
+	chainCode []byte
+}
+
+// NewHDWallet makes HDWallet of mnemonic and optional passphrase
+func NewHDWallet(mnemonic string, passphrase string) (*HDWallet, error) {
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewHDWalletFromSeed(seed)
+}
+
+// NewHDWalletFromSeed makes HDWallet of seed, whose length is between 16 and 64 bytes
+func NewHDWalletFromSeed(seed []byte) (*HDWallet, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length %v", len(seed))
+	}
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key := new(big.Int).SetBytes(sum[:32])
+	if key.Sign() == 0 || key.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, fmt.Errorf("invalid master key, use another seed")
+	}
+	return &HDWallet{master: &extendedKey{key: key, chainCode: sum[32:]}}, nil
+}
+
+// child derives child key at index, index >= 0x80000000 is hardened
+func (k *extendedKey) child(index uint32) (*extendedKey, error) {
+	var data []byte
+	if index >= hardenedOffset {
+		data = append([]byte{0}, common.LeftPadBytes(k.key.Bytes(), 32)...)
+	} else {
+		privK, err := crypto.ToECDSA(common.LeftPadBytes(k.key.Bytes(), 32))
+		if err != nil {
+			return nil, err
+		}
+		data = crypto.CompressPubkey(&privK.PublicKey)
+	}
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	data = append(data, indexBytes[:]...)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := crypto.S256().Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("invalid child key at index %v", index)
+	}
+	key := il.Add(il, k.key)
+	key.Mod(key, n)
+	if key.Sign() == 0 {
+		return nil, fmt.Errorf("invalid child key at index %v", index)
+	}
+	return &extendedKey{key: key, chainCode: sum[32:]}, nil
+}
+
+// DeriveKey derives private key at path such as m/44'/60'/0'/0/0
+func (w *HDWallet) DeriveKey(path string) (*ecdsa.PrivateKey, error) {
+	indexes, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	k := w.master
+	for _, index := range indexes {
+		k, err = k.child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return crypto.ToECDSA(common.LeftPadBytes(k.key.Bytes(), 32))
+}
+
+// Derive derives signer of key at path
+func (w *HDWallet) Derive(path string) (*KeySigner, error) {
+	privK, err := w.DeriveKey(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(privK), nil
+}
+
+// Account derives signer of the i-th ethereum account at m/44'/60'/0'/0/i, as common wallets do
+func (w *HDWallet) Account(i uint32) (*KeySigner, error) {
+	return w.Derive(fmt.Sprintf("%v/%d", DefaultDerivationPath, i))
+}
@@ -0,0 +1,115 @@
+package sdk
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestMnemonic(t *testing.T) {
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		mnemonic, err := NewMnemonic(bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if words := len(strings.Fields(mnemonic)); words != bits/32*3 {
+			t.Fatalf("%v bits: %v words", bits, words)
+		}
+		if err := ValidateMnemonic(mnemonic); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := NewMnemonic(100); err == nil {
+		t.Fatal("expect error of invalid entropy size")
+	}
+
+	invalid := []string{
+		// bad checksum
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		// unknown word
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon ethereum",
+		// bad length
+		"abandon abandon abandon",
+	}
+	for _, mnemonic := range invalid {
+		if err := ValidateMnemonic(mnemonic); err == nil {
+			t.Fatalf("expect error of %q", mnemonic)
+		}
+	}
+}
+
+func TestMnemonicToSeed(t *testing.T) {
+	// BIP-39 test vector
+	seed, err := MnemonicToSeed(testMnemonic, "TREZOR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"
+	if hex.EncodeToString(seed) != want {
+		t.Fatalf("seed: %x", seed)
+	}
+}
+
+func TestBIP32Vector(t *testing.T) {
+	// BIP-32 test vector 1
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	w, err := NewHDWalletFromSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		path string
+		key  string
+	}{
+		{"m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{"m/0'/1/2'/2", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for _, c := range cases {
+		if c.path == "m" {
+			if got := fmt.Sprintf("%064x", w.master.key); got != c.key {
+				t.Fatalf("master key: %v", got)
+			}
+			continue
+		}
+		privK, err := w.DeriveKey(c.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(crypto.FromECDSA(privK)); got != c.key {
+			t.Fatalf("key of %v: %v want %v", c.path, got, c.key)
+		}
+	}
+}
+
+func TestHDWalletAccount(t *testing.T) {
+	w, err := NewHDWallet(testMnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"0x9858EfFD232B4033E47d90003D41EC34EcaEda94",
+		"0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0",
+	}
+	for i, address := range want {
+		signer, err := w.Account(uint32(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer.Address().String() != address {
+			t.Fatalf("account %v: %v want %v", i, signer.Address().String(), address)
+		}
+	}
+
+	if _, err := NewHDWallet(testMnemonic+" abandon", ""); err == nil {
+		t.Fatal("expect error of invalid mnemonic")
+	}
+}
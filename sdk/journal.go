@@ -0,0 +1,406 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+)
+
+// JournalState is the lifecycle state of a journaled transaction
+type JournalState string
+
+const (
+	// JournalPending tx is written before broadcast, and waits to be mined
+	JournalPending JournalState = "pending"
+	// JournalMined tx is mined with tm.confirmations and succeeded
+	JournalMined JournalState = "mined"
+	// JournalReverted tx is mined with tm.confirmations and reverted
+	JournalReverted JournalState = "reverted"
+	// JournalFailed node refused the broadcast, e.g. nonce too low or insufficient funds.
+	// tx stays pending when the broadcast failed without an answer of node, such as timeout, as node may have it
+	JournalFailed JournalState = "failed"
+	// JournalDropped node forgot tx and its nonce is used by another one, it will never be mined
+	JournalDropped JournalState = "dropped"
+)
+
+// JournalEntry is a signed transaction recorded in Journal
+type JournalEntry struct {
+	Hash    string       `json:"hash"`
+	From    string       `json:"from"`
+	Nonce   uint64       `json:"nonce"`
+	Label   string       `json:"label,omitempty"`
+	State   JournalState `json:"state"`
+	Raw     string       `json:"raw"` // 0x hex of binary encoding of signed tx
+	Updated time.Time    `json:"updated"`
+}
+
+// Journal is an append-only file of signed transactions, one JSON entry per line,
+// the last line of a hash is its current state
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	entries map[string]*JournalEntry
+	order   []string
+}
+
+// OpenJournal opens journal at path, it's created when missing
+// the file is compacted to one line per transaction, a broken last line written by a crash is skipped
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{path: path, entries: make(map[string]*JournalEntry)}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	if err := j.compact(); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("open journal error: %s", err.Error())
+	}
+	j.file = file
+	return j, nil
+}
+
+func (j *Journal) load() error {
+	file, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open journal error: %s", err.Error())
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Hash == "" {
+			logrus.Warnf("skip broken line of journal %v", j.path)
+			continue
+		}
+		j.set(&entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read journal error: %s", err.Error())
+	}
+	return nil
+}
+
+// compact rewrites the file with current entries, the old file is replaced by rename so it's never half written
+func (j *Journal) compact() error {
+	tmp := j.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("compact journal error: %s", err.Error())
+	}
+	w := bufio.NewWriter(file)
+	for _, hash := range j.order {
+		line, err := json.Marshal(j.entries[hash])
+		if err == nil {
+			_, err = w.Write(append(line, '\n'))
+		}
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("compact journal error: %s", err.Error())
+		}
+	}
+	err = w.Flush()
+	if err == nil {
+		err = file.Sync()
+	}
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return fmt.Errorf("compact journal error: %s", err.Error())
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return fmt.Errorf("compact journal error: %s", err.Error())
+	}
+	return nil
+}
+
+func (j *Journal) set(entry *JournalEntry) {
+	if _, ok := j.entries[entry.Hash]; !ok {
+		j.order = append(j.order, entry.Hash)
+	}
+	j.entries[entry.Hash] = entry
+}
+
+// write appends entry and syncs it to disk
+func (j *Journal) write(entry JournalEntry) error {
+	entry.Updated = time.Now()
+	line, err := json.Marshal(&entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return fmt.Errorf("journal %v is closed", j.path)
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := j.file.Sync(); err != nil {
+		return err
+	}
+	j.set(&entry)
+	return nil
+}
+
+// update writes new state of hash
+func (j *Journal) update(hash string, state JournalState) error {
+	entry, ok := j.Get(hash)
+	if !ok {
+		return fmt.Errorf("transaction %v is not in journal", hash)
+	}
+	entry.State = state
+	return j.write(entry)
+}
+
+// Get returns entry of hash
+func (j *Journal) Get(hash string) (JournalEntry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry, ok := j.entries[hash]
+	if !ok {
+		return JournalEntry{}, false
+	}
+	return *entry, true
+}
+
+// Entries returns all entries in the order they are written first
+func (j *Journal) Entries() []JournalEntry {
+	return j.filter("")
+}
+
+// Pending returns entries not mined yet
+func (j *Journal) Pending() []JournalEntry {
+	return j.filter(JournalPending)
+}
+
+func (j *Journal) filter(state JournalState) []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var entries []JournalEntry
+	for _, hash := range j.order {
+		if entry := j.entries[hash]; state == "" || entry.State == state {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries
+}
+
+// Close closes the file of journal
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+type journalLabelKey struct{}
+
+// WithJournalLabel returns ctx carrying label, transactions sent with it are journaled with the label
+// e.g. tm.SendTxContext(sdk.WithJournalLabel(ctx, "withdraw#1024"), ...)
+func WithJournalLabel(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, journalLabelKey{}, label)
+}
+
+func journalLabel(ctx context.Context) string {
+	label, _ := ctx.Value(journalLabelKey{}).(string)
+	return label
+}
+
+// EnableJournal makes tm write every signed transaction to journal at path before broadcast,
+// then resumes pending transactions of the journal: they are watched until mined,
+// and re-broadcast when node has forgotten them
+func (tm *TransactionManager) EnableJournal(path string) error {
+	return tm.EnableJournalContext(context.Background(), path)
+}
+
+// EnableJournalContext is like EnableJournal but with ctx for cancellation and deadline
+func (tm *TransactionManager) EnableJournalContext(ctx context.Context, path string) error {
+	journal, err := OpenJournal(path)
+	if err != nil {
+		return err
+	}
+	// watchers of the old journal are stopped before it's closed
+	tm.stopJournal()
+	tm.journalMu.Lock()
+	tm.journal = journal
+	tm.journalCtx, tm.journalCancel = context.WithCancel(context.Background())
+	tm.journalWatchers = new(sync.WaitGroup)
+	tm.journalMu.Unlock()
+
+	for _, entry := range journal.Pending() {
+		if err := tm.resumeJournal(ctx, journal, entry); err != nil {
+			// stays pending and is resumed at next start
+			logrus.Errorf("resume transaction %v of journal error: %v", entry.Hash, err)
+		}
+	}
+	return nil
+}
+
+// Journal returns journal of tm, nil when it's not enabled
+func (tm *TransactionManager) Journal() *Journal {
+	tm.journalMu.Lock()
+	defer tm.journalMu.Unlock()
+	return tm.journal
+}
+
+// stopJournal stops watchers of journal and waits them to exit, then closes journal
+func (tm *TransactionManager) stopJournal() {
+	tm.journalMu.Lock()
+	journal, cancel, watchers := tm.journal, tm.journalCancel, tm.journalWatchers
+	tm.journal, tm.journalCtx, tm.journalCancel, tm.journalWatchers = nil, nil, nil, nil
+	tm.journalMu.Unlock()
+	if journal == nil {
+		return
+	}
+	cancel()
+	watchers.Wait()
+	journal.Close()
+}
+
+// goWatchJournal watches hash of journal in a goroutine, unless journal is closed or replaced
+func (tm *TransactionManager) goWatchJournal(journal *Journal, hash common.Hash) {
+	tm.journalMu.Lock()
+	defer tm.journalMu.Unlock()
+	if tm.journal != journal {
+		return
+	}
+	ctx, watchers := tm.journalCtx, tm.journalWatchers
+	watchers.Add(1)
+	go func() {
+		defer watchers.Done()
+		tm.watchJournal(ctx, journal, hash)
+	}()
+}
+
+// resumeJournal finishes entry mined already, or watches it, re-broadcasting it when node doesn't know it
+func (tm *TransactionManager) resumeJournal(ctx context.Context, journal *Journal, entry JournalEntry) error {
+	raw, err := hexutil.Decode(entry.Raw)
+	if err != nil {
+		return err
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return err
+	}
+	tm.sent.add(tx)
+
+	var receipt *types.Receipt
+	err = tm.retry.Do(ctx, func() (err error) {
+		receipt, err = tm.Client.TransactionReceipt(ctx, tx.Hash())
+		return
+	})
+	if err == nil {
+		return journal.update(entry.Hash, journalReceiptState(receipt))
+	}
+	if err != ethereum.NotFound {
+		return fmt.Errorf("TransactionReceipt() error: %s", err.Error())
+	}
+
+	err = tm.retry.Do(ctx, func() (err error) {
+		_, _, err = tm.Client.TransactionByHash(ctx, tx.Hash())
+		return
+	})
+	if err == ethereum.NotFound {
+		err = sendSignedTx(ctx, tm.retry, tm.Client, tx)
+		if isNonceTooLow(err) {
+			return journal.update(entry.Hash, JournalDropped)
+		}
+		if isAlreadyKnown(err) {
+			err = nil
+		}
+		if err == nil {
+			logrus.Infof("transaction %v of journal is re-broadcast", entry.Hash)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	tm.goWatchJournal(journal, tx.Hash())
+	return nil
+}
+
+func journalReceiptState(receipt *types.Receipt) JournalState {
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return JournalMined
+	}
+	return JournalReverted
+}
+
+// watchJournal waits hash until it's mined with tm.confirmations, tm.timeout seconds passed or ctx is done,
+// and writes its state to journal. it stays pending when not mined, and is resumed at next start
+func (tm *TransactionManager) watchJournal(ctx context.Context, journal *Journal, hash common.Hash) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(tm.timeout))
+	defer cancel()
+	receipt, err := tm.waiter.wait(ctx, []common.Hash{hash}, tm.confirmations)
+	if err != nil {
+		return
+	}
+	tm.replacements.remove(hash)
+	if err := journal.update(hash.String(), journalReceiptState(receipt)); err != nil {
+		logrus.Errorf("write journal of transaction %v error: %v", hash.String(), err)
+	}
+}
+
+// broadcast sends tx signed by from, and keeps it for re-broadcasting unless node refused it
+// when journal is enabled, tx is written to it before sending, and watched after sending.
+// it's marked failed only when node refused it, otherwise node may have it and it's watched as well
+func (tm *TransactionManager) broadcast(ctx context.Context, from common.Address, tx *types.Transaction) error {
+	journal := tm.Journal()
+	if journal != nil {
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		entry := JournalEntry{
+			Hash:  tx.Hash().String(),
+			From:  from.String(),
+			Nonce: tx.Nonce(),
+			Label: journalLabel(ctx),
+			State: JournalPending,
+			Raw:   hexutil.Encode(raw),
+		}
+		if err := journal.write(entry); err != nil {
+			return fmt.Errorf("write journal error: %s", err.Error())
+		}
+	}
+
+	err := sendSignedTx(ctx, tm.retry, tm.Client, tx)
+	if err != nil && isRejected(err) {
+		if journal != nil {
+			if e := journal.update(tx.Hash().String(), JournalFailed); e != nil {
+				logrus.Errorf("write journal of transaction %v error: %v", tx.Hash().String(), e)
+			}
+		}
+		return err
+	}
+	// tx may be sent, so it can be re-broadcast after reorg
+	tm.sent.add(tx)
+	if journal != nil {
+		tm.goWatchJournal(journal, tx.Hash())
+	}
+	return err
+}
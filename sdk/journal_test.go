@@ -0,0 +1,244 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// waitJournalState waits state of hash is written by the watching goroutine
+func waitJournalState(t *testing.T, journal *Journal, hash string, state JournalState) {
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if entry, _ := journal.Get(hash); entry.State == state {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	entry, _ := journal.Get(hash)
+	t.Fatalf("state of %v is %v, want %v", hash, entry.State, state)
+}
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "txs.journal")
+
+	backend := newTestBackend(t)
+	defer backend.close()
+	backend.set(func(b *testBackend) {
+		b.sendErr = func(tx *types.Transaction) error {
+			if tx.Nonce() == 2 {
+				return errors.New("insufficient funds for gas * price + value")
+			}
+			return nil
+		}
+	})
+	tm, closeFn := backend.newTM(t)
+	defer closeFn()
+	if err := tm.EnableJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithJournalLabel(context.Background(), "withdraw#1")
+	hash, err := tm.SendTxContext(ctx, signer, testAddress(1), big.NewInt(1), nil, nil, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := tm.Journal().Get(hash)
+	if !ok || entry.State != JournalPending || entry.Label != "withdraw#1" || entry.Nonce != 1 || entry.From != signer.Address().String() {
+		t.Fatalf("entry: %+v", entry)
+	}
+	decoded, err := DecodeRawTx(entry.Raw)
+	if err != nil || decoded.Hash != hash {
+		t.Fatalf("raw of entry: %v %v", decoded, err)
+	}
+	backend.mine(common.HexToHash(hash))
+	waitJournalState(t, tm.Journal(), hash, JournalMined)
+
+	// refused by node
+	if _, err := tm.SendTx(signer, testAddress(1), big.NewInt(1), nil, nil, 2, 0); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expect ErrInsufficientFunds, got %v", err)
+	}
+	entries := tm.Journal().Entries()
+	if len(entries) != 2 || entries[1].State != JournalFailed || entries[1].Nonce != 2 {
+		t.Fatalf("entries: %+v", entries)
+	}
+	if tm.sent.get(common.HexToHash(entries[1].Hash)) != nil {
+		t.Fatal("refused tx is kept for re-broadcasting")
+	}
+
+	// states survive reopening
+	tm.Journal().Close()
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	entries = journal.Entries()
+	if len(entries) != 2 || entries[0].State != JournalMined || entries[0].Label != "withdraw#1" || entries[1].State != JournalFailed {
+		t.Fatalf("reopened entries: %+v", entries)
+	}
+}
+
+func TestJournalResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "txs.journal")
+
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := newTestBackend(t)
+	defer backend.close()
+
+	// the process crashes with 5 pending transactions
+	tm, closeFn := backend.newTM(t)
+	if err := tm.EnableJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	var hashes []common.Hash
+	for nonce := uint64(1); nonce <= 5; nonce++ {
+		hash, err := tm.SendTx(signer, testAddress(1), big.NewInt(1), nil, nil, nonce, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, common.HexToHash(hash))
+	}
+	closeFn()
+	// a line broken by the crash
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"hash":"0x12`)
+	file.Close()
+
+	backend.set(func(b *testBackend) {
+		b.status = func(tx *types.Transaction) uint64 {
+			if tx.Hash() == hashes[1] {
+				return types.ReceiptStatusFailed
+			}
+			return types.ReceiptStatusSuccessful
+		}
+		b.sendErr = func(tx *types.Transaction) error {
+			if tx.Nonce() == 5 {
+				return errors.New("nonce too low")
+			}
+			return nil
+		}
+	})
+	backend.mine(hashes[0], hashes[1])
+	// hashes[2] is still in the pool
+	backend.forget(hashes[3])
+	backend.forget(hashes[4])
+	sends := backend.count("eth_sendRawTransaction")
+
+	tm, closeFn = backend.newTM(t)
+	defer closeFn()
+	if err := tm.EnableJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	journal := tm.Journal()
+	if len(journal.Entries()) != 5 {
+		t.Fatalf("%v entries", len(journal.Entries()))
+	}
+	if entry, _ := journal.Get(hashes[0].String()); entry.State != JournalMined {
+		t.Fatalf("entry 0: %+v", entry)
+	}
+	if entry, _ := journal.Get(hashes[1].String()); entry.State != JournalReverted {
+		t.Fatalf("entry 1: %+v", entry)
+	}
+	if entry, _ := journal.Get(hashes[4].String()); entry.State != JournalDropped {
+		t.Fatalf("entry 4: %+v", entry)
+	}
+	// hashes[3] and hashes[4] are re-broadcast
+	if n := backend.count("eth_sendRawTransaction") - sends; n != 2 {
+		t.Fatalf("%v transactions are re-broadcast", n)
+	}
+	backend.set(func(b *testBackend) {
+		if b.pool[hashes[3]] == nil {
+			t.Fatal("transaction is not re-broadcast")
+		}
+	})
+
+	// the resumed ones are watched
+	backend.mine(hashes[2], hashes[3])
+	waitJournalState(t, journal, hashes[2].String(), JournalMined)
+	waitJournalState(t, journal, hashes[3].String(), JournalMined)
+}
+
+func TestJournalUnanswered(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "txs.journal")
+
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := newTestBackend(t)
+	tm, closeFn := backend.newTM(t)
+	if err := tm.EnableJournal(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// node may have got the tx, so it stays pending
+	backend.close()
+	hash, err := tm.SendTx(signer, testAddress(1), big.NewInt(1), nil, nil, 1, 0)
+	if err == nil {
+		t.Fatal("expect error of the closed node")
+	}
+	if entry, _ := tm.Journal().Get(hash); entry.State != JournalPending {
+		t.Fatalf("entry: %+v", entry)
+	}
+	// and it can be re-broadcast after reorg
+	if tm.sent.get(common.HexToHash(hash)) == nil {
+		t.Fatal("tx maybe sent is not kept for re-broadcasting")
+	}
+
+	// Close stops the watcher before closing journal
+	done := make(chan struct{})
+	go func() {
+		closeFn()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close doesn't stop watchers of journal")
+	}
+	if tm.Journal() != nil {
+		t.Fatal("journal is not closed")
+	}
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	if pending := journal.Pending(); len(pending) != 1 || pending[0].Hash != hash {
+		t.Fatalf("pending: %+v", pending)
+	}
+}
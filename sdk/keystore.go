@@ -0,0 +1,303 @@
+package sdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// ScryptParams are the cost parameters of scrypt encrypting keystore files, zero value means StandardScrypt
+type ScryptParams struct {
+	N int
+	P int
+}
+
+var (
+	// StandardScrypt uses 256MB memory and takes about 1 second, the same as geth
+	StandardScrypt = ScryptParams{N: keystore.StandardScryptN, P: keystore.StandardScryptP}
+	// LightScrypt uses 4MB memory and takes about 100ms, for weak devices and tests
+	LightScrypt = ScryptParams{N: keystore.LightScryptN, P: keystore.LightScryptP}
+)
+
+// KeystoreAccount is an account stored in a keystore file
+type KeystoreAccount struct {
+	Address string `json:"address"`
+	File    string `json:"file"`
+}
+
+// NewKeystoreAccount makes a new account encrypted with passphrase in keydir
+func NewKeystoreAccount(keydir string, passphrase string, params ScryptParams) (*KeystoreAccount, error) {
+	privK, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return storeKey(keydir, privK, passphrase, params)
+}
+
+// ImportKeystoreAccount stores sk (e.g. output of GenAccount) encrypted with passphrase in keydir
+func ImportKeystoreAccount(keydir string, sk []byte, passphrase string, params ScryptParams) (*KeystoreAccount, error) {
+	privK, err := crypto.ToECDSA(sk)
+	if err != nil {
+		return nil, err
+	}
+	return storeKey(keydir, privK, passphrase, params)
+}
+
+// ImportHexKeystoreAccount stores hex private key encrypted with passphrase in keydir
+func ImportHexKeystoreAccount(keydir string, hexPrivKey string, passphrase string, params ScryptParams) (*KeystoreAccount, error) {
+	privK, _, _, err := HexToAccount(hexPrivKey)
+	if err != nil {
+		return nil, fmt.Errorf("convert hex sk to ECDSA error: %s", err.Error())
+	}
+	return storeKey(keydir, privK, passphrase, params)
+}
+
+// ListKeystoreAccounts lists accounts in keydir, such as keystore directory of geth datadir
+// files which are not keystore files are skipped
+func ListKeystoreAccounts(keydir string) ([]KeystoreAccount, error) {
+	files, err := ioutil.ReadDir(keydir)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []KeystoreAccount
+	for _, fi := range files {
+		// skip directories, editor backups and hidden files as geth does
+		name := fi.Name()
+		if fi.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+		file := filepath.Join(keydir, name)
+		keyJSON, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var key struct {
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal(keyJSON, &key); err != nil || !common.IsHexAddress(key.Address) {
+			continue
+		}
+		accounts = append(accounts, KeystoreAccount{
+			Address: common.HexToAddress(key.Address).String(),
+			File:    file,
+		})
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].File < accounts[j].File })
+	return accounts, nil
+}
+
+// ChangePassphrase re-encrypts keystore file utcFile with newPassphrase, scrypt parameters of the file are kept
+func ChangePassphrase(utcFile string, oldPassphrase string, newPassphrase string) error {
+	keyJSON, err := ioutil.ReadFile(utcFile)
+	if err != nil {
+		return err
+	}
+	return reencrypt(utcFile, keyJSON, oldPassphrase, newPassphrase, keyScryptParams(keyJSON))
+}
+
+// ReencryptKeystore re-encrypts keystore file utcFile with scrypt parameters params, passphrase is unchanged
+func ReencryptKeystore(utcFile string, passphrase string, params ScryptParams) error {
+	keyJSON, err := ioutil.ReadFile(utcFile)
+	if err != nil {
+		return err
+	}
+	return reencrypt(utcFile, keyJSON, passphrase, passphrase, params)
+}
+
+func reencrypt(utcFile string, keyJSON []byte, oldPassphrase string, newPassphrase string, params ScryptParams) error {
+	key, err := keystore.DecryptKey(keyJSON, oldPassphrase)
+	if err != nil {
+		return err
+	}
+	defer zeroKey(key.PrivateKey)
+
+	params = params.orStandard()
+	newJSON, err := keystore.EncryptKey(key, newPassphrase, params.N, params.P)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(utcFile, newJSON)
+}
+
+// keyScryptParams returns scrypt parameters of keyJSON, StandardScrypt when it's not encrypted by scrypt
+func keyScryptParams(keyJSON []byte) ScryptParams {
+	// field names are matched case-insensitively, so both "crypto" and "Crypto" of version 3 work
+	var key struct {
+		Crypto struct {
+			KDF       string `json:"kdf"`
+			KDFParams struct {
+				N int `json:"n"`
+				P int `json:"p"`
+			} `json:"kdfparams"`
+		} `json:"crypto"`
+	}
+	if err := json.Unmarshal(keyJSON, &key); err != nil || key.Crypto.KDF != "scrypt" {
+		return StandardScrypt
+	}
+	return ScryptParams{N: key.Crypto.KDFParams.N, P: key.Crypto.KDFParams.P}
+}
+
+func (p ScryptParams) orStandard() ScryptParams {
+	if p.N == 0 || p.P == 0 {
+		return StandardScrypt
+	}
+	return p
+}
+
+func storeKey(keydir string, privK *ecdsa.PrivateKey, passphrase string, params ScryptParams) (*KeystoreAccount, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	key := &keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(privK.PublicKey),
+		PrivateKey: privK,
+	}
+	params = params.orStandard()
+	keyJSON, err := keystore.EncryptKey(key, passphrase, params.N, params.P)
+	if err != nil {
+		return nil, err
+	}
+	file := filepath.Join(keydir, keyFileName(key.Address))
+	if err := writeKeyFile(file, keyJSON); err != nil {
+		return nil, err
+	}
+	return &KeystoreAccount{Address: key.Address.String(), File: file}, nil
+}
+
+// keyFileName returns file name of keystore file as geth names it: UTC--<created_at UTC ISO8601>--<address hex>
+func keyFileName(address common.Address) string {
+	ts := time.Now().UTC()
+	return fmt.Sprintf("UTC--%s--%s", ts.Format("2006-01-02T15-04-05.000000000Z"), hex.EncodeToString(address[:]))
+}
+
+// writeKeyFile writes content to a temporary file then renames it to file, so file is never half written
+func writeKeyFile(file string, content []byte) error {
+	dir := filepath.Dir(file)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
+
+func zeroKey(k *ecdsa.PrivateKey) {
+	b := k.D.Bits()
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// UnlockedSigner signs with a key decrypted from keystore file until it's locked
+type UnlockedSigner struct {
+	mu      sync.Mutex
+	key     *ecdsa.PrivateKey
+	address common.Address
+	timer   *time.Timer
+}
+
+// UnlockKeystore decrypts keystore file utcFile with passphrase for signing,
+// the key is wiped from memory after timeout, set timeout to 0 to keep it until Lock is called
+func UnlockKeystore(utcFile string, passphrase string, timeout time.Duration) (*UnlockedSigner, error) {
+	keyJSON, err := ioutil.ReadFile(utcFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	s := &UnlockedSigner{
+		key:     key.PrivateKey,
+		address: key.Address,
+	}
+	if timeout > 0 {
+		s.timer = time.AfterFunc(timeout, s.Lock)
+	}
+	return s, nil
+}
+
+// Lock wipes the key, SignTx fails after it
+func (s *UnlockedSigner) Lock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	if s.key != nil {
+		zeroKey(s.key)
+		s.key = nil
+	}
+}
+
+// Unlocked reports whether s can still sign
+func (s *UnlockedSigner) Unlocked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.key != nil
+}
+
+func (s *UnlockedSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *UnlockedSigner) SignTx(ctx context.Context, txSigner types.Signer, tx *types.Transaction) (*types.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.key == nil {
+		return nil, fmt.Errorf("account %v is locked", s.address.String())
+	}
+	return types.SignTx(tx, txSigner, s.key)
+}
+
+// signHash signs hash with the unlocked key, return 65 bytes signature with V 27 or 28
+func (s *UnlockedSigner) signHash(hash []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.key == nil {
+		return nil, fmt.Errorf("account %v is locked", s.address.String())
+	}
+	return signHash(s.key, hash)
+}
+
+// String shows address only, so that the key never ends up in logs
+func (s *UnlockedSigner) String() string {
+	return fmt.Sprintf("UnlockedSigner(%v)", s.address.String())
+}
+
+// GoString is like String for %#v
+func (s *UnlockedSigner) GoString() string {
+	return s.String()
+}
@@ -0,0 +1,140 @@
+package sdk
+
+import (
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestKeystoreLifecycle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	created, err := NewKeystoreAccount(dir, "pass1", LightScrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk, _, _, err := GenAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := ImportKeystoreAccount(dir, sk, "pass2", LightScrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportHexKeystoreAccount(dir, testSignerKey, "pass3", LightScrypt); err != nil {
+		t.Fatal(err)
+	}
+	// not keystore files
+	ioutil.WriteFile(filepath.Join(dir, "README"), []byte("hello"), 0600)
+	os.Mkdir(filepath.Join(dir, "sub"), 0700)
+
+	accounts, err := ListKeystoreAccounts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 3 {
+		t.Fatalf("accounts: %v", accounts)
+	}
+
+	// exported key of imported account is sk
+	account, err := ExportAccountObject(imported.File, "pass2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.PrivateKey != hex.EncodeToString(sk) {
+		t.Fatalf("exported key: %v", account.PrivateKey)
+	}
+
+	if err := ChangePassphrase(created.File, "wrong", "new"); err == nil {
+		t.Fatal("expect error of wrong passphrase")
+	}
+	if err := ChangePassphrase(created.File, "pass1", "new"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExportAccount(created.File, "pass1"); err == nil {
+		t.Fatal("old passphrase still works")
+	}
+	keyJSON, _ := ioutil.ReadFile(created.File)
+	if params := keyScryptParams(keyJSON); params != LightScrypt {
+		t.Fatalf("scrypt params are not kept: %v", params)
+	}
+
+	custom := ScryptParams{N: 1 << 10, P: 2}
+	if err := ReencryptKeystore(created.File, "new", custom); err != nil {
+		t.Fatal(err)
+	}
+	keyJSON, _ = ioutil.ReadFile(created.File)
+	if params := keyScryptParams(keyJSON); params != custom {
+		t.Fatalf("scrypt params: %v", params)
+	}
+	signer, err := NewKeystoreSigner(created.File, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Address().String() != created.Address {
+		t.Fatalf("address: %v want %v", signer.Address().String(), created.Address)
+	}
+
+	// no temporary file is left
+	accounts, _ = ListKeystoreAccounts(dir)
+	if len(accounts) != 3 {
+		t.Fatalf("accounts after re-encrypting: %v", accounts)
+	}
+}
+
+func TestUnlockKeystore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	account, err := NewKeystoreAccount(dir, "pass", LightScrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UnlockKeystore(account.File, "wrong", time.Second); err == nil {
+		t.Fatal("expect error of wrong passphrase")
+	}
+
+	signer, err := UnlockKeystore(account.File, "pass", 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Address().String() != account.Address {
+		t.Fatalf("address: %v", signer.Address().String())
+	}
+	txSigner := types.NewLondonSigner(big.NewInt(5))
+	tx := testTxs(big.NewInt(5))[0]
+	if _, err := signer.SignTx(context.Background(), txSigner, tx); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if signer.Unlocked() {
+		t.Fatal("signer is still unlocked")
+	}
+	if _, err := signer.SignTx(context.Background(), txSigner, tx); err == nil {
+		t.Fatal("expect error of locked account")
+	}
+
+	forever, err := UnlockKeystore(account.File, "pass", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forever.Lock()
+	if _, err := forever.SignTx(context.Background(), txSigner, tx); err == nil {
+		t.Fatal("expect error of locked account")
+	}
+}
@@ -0,0 +1,136 @@
+package sdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// HashMessage returns EIP-191 hash of msg: keccak256("\x19Ethereum Signed Message:\n" + len(msg) + msg)
+func HashMessage(msg []byte) []byte {
+	return accounts.TextHash(msg)
+}
+
+// MessageSigner is a Signer which also signs EIP-191 messages and EIP-712 typed data,
+// KeySigner, UnlockedSigner and ExternalSigner implement it
+type MessageSigner interface {
+	Signer
+	// SignMessage signs msg with EIP-191 prefix, return 65 bytes signature with V 27 or 28
+	SignMessage(ctx context.Context, msg []byte) ([]byte, error)
+	// SignTypedData signs EIP-712 typed data, return 65 bytes signature with V 27 or 28
+	SignTypedData(ctx context.Context, td *TypedData) ([]byte, error)
+}
+
+// SignMessage signs msg with EIP-191 prefix as personal_sign does,
+// return 65 bytes signature [R || S || V] with V 27 or 28
+func SignMessage(signer MessageSigner, msg []byte) ([]byte, error) {
+	return SignMessageContext(context.Background(), signer, msg)
+}
+
+// SignMessageContext is like SignMessage but with ctx for cancellation and deadline
+func SignMessageContext(ctx context.Context, signer MessageSigner, msg []byte) ([]byte, error) {
+	return signer.SignMessage(ctx, msg)
+}
+
+// SignText is like SignMessage but signs text
+func SignText(signer MessageSigner, text string) ([]byte, error) {
+	return SignMessage(signer, []byte(text))
+}
+
+func (s *KeySigner) SignMessage(ctx context.Context, msg []byte) ([]byte, error) {
+	return signHash(s.key, HashMessage(msg))
+}
+
+func (s *UnlockedSigner) SignMessage(ctx context.Context, msg []byte) ([]byte, error) {
+	return s.signHash(HashMessage(msg))
+}
+
+func (s *ExternalSigner) SignMessage(ctx context.Context, msg []byte) ([]byte, error) {
+	var sig hexutil.Bytes
+	// pointer makes MixedcaseAddress marshal as string
+	address := common.NewMixedcaseAddress(s.address)
+	if err := s.client.CallContext(ctx, &sig, "account_signData", accounts.MimetypeTextPlain, &address, hexutil.Bytes(msg)); err != nil {
+		return nil, fmt.Errorf("account_signData error: %s", err.Error())
+	}
+	return s.checkSignature(HashMessage(msg), sig)
+}
+
+// checkSignature makes sure sig of hash returned by the external signer is signed by s.address,
+// it returns sig with V 27 or 28
+func (s *ExternalSigner) checkSignature(hash []byte, sig []byte) ([]byte, error) {
+	signer, err := recoverHashSigner(hash, sig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature of external signer: %s", err.Error())
+	}
+	if signer != s.address {
+		return nil, fmt.Errorf("data is signed by %v instead of %v", signer.String(), s.address.String())
+	}
+	sig = common.CopyBytes(sig)
+	if sig[crypto.RecoveryIDOffset] < 27 {
+		sig[crypto.RecoveryIDOffset] += 27
+	}
+	return sig, nil
+}
+
+// signHash signs hash with key, return 65 bytes signature with V 27 or 28
+func signHash(key *ecdsa.PrivateKey, hash []byte) ([]byte, error) {
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// RecoverMessageSigner recovers address of signer of msg signed with EIP-191 prefix,
+// V of sig can be either 27/28 or 0/1
+func RecoverMessageSigner(msg []byte, sig []byte) (common.Address, error) {
+	return recoverHashSigner(HashMessage(msg), sig)
+}
+
+// recoverHashSigner recovers address of signer of hash, V of sig can be either 27/28 or 0/1
+func recoverHashSigner(hash []byte, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length %v, expect %v", len(sig), crypto.SignatureLength)
+	}
+	sig = common.CopyBytes(sig)
+	v := sig[crypto.RecoveryIDOffset]
+	if v == 27 || v == 28 {
+		v -= 27
+	}
+	if v != 0 && v != 1 {
+		return common.Address{}, fmt.Errorf("invalid signature recovery id %v", sig[crypto.RecoveryIDOffset])
+	}
+	sig[crypto.RecoveryIDOffset] = v
+
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	// reject malleable signatures with high S
+	if !crypto.ValidateSignatureValues(v, r, s, true) {
+		return common.Address{}, fmt.Errorf("invalid signature values")
+	}
+	pubK, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubK), nil
+}
+
+// VerifyMessage reports whether sig of msg is signed by address
+func VerifyMessage(address string, msg []byte, sig []byte) (bool, error) {
+	signer, err := RecoverMessageSigner(msg, sig)
+	if err != nil {
+		return false, err
+	}
+	return signer == common.HexToAddress(address), nil
+}
+
+// VerifyText is like VerifyMessage but for text
+func VerifyText(address string, text string, sig []byte) (bool, error) {
+	return VerifyMessage(address, []byte(text), sig)
+}
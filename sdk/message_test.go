@@ -0,0 +1,128 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestHashMessage(t *testing.T) {
+	want := "50b2c43fd39106bafbba0da34fc430e1f91e3c96ea2acee2bc34119f92b37750"
+	if got := hex.EncodeToString(HashMessage([]byte("hello"))); got != want {
+		t.Fatalf("hash: %v want %v", got, want)
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	_, _, address, err := HexToAccount(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "login nonce: 8f2a"
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignText(signer, text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != 65 || (sig[64] != 27 && sig[64] != 28) {
+		t.Fatalf("signature: %x", sig)
+	}
+
+	ok, err := VerifyText(address.String(), text, sig)
+	if err != nil || !ok {
+		t.Fatalf("verify: %v %v", ok, err)
+	}
+
+	// v as 0/1
+	sig01 := common.CopyBytes(sig)
+	sig01[64] -= 27
+	recovered, err := RecoverMessageSigner([]byte(text), sig01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered != address {
+		t.Fatalf("recovered %v want %v", recovered.String(), address.String())
+	}
+	if sig01[64] > 1 {
+		t.Fatal("signature is modified")
+	}
+
+	ok, err = VerifyText(address.String(), text+"!", sig)
+	if err != nil || ok {
+		t.Fatalf("verify tampered text: %v %v", ok, err)
+	}
+	ok, err = VerifyText("0x00000000000000000000000000000000000000bb", text, sig)
+	if err != nil || ok {
+		t.Fatalf("verify with other address: %v %v", ok, err)
+	}
+
+	bad := common.CopyBytes(sig)
+	bad[64] = 5
+	if _, err := RecoverMessageSigner([]byte(text), bad); err == nil {
+		t.Fatal("expect error of invalid v")
+	}
+	if _, err := RecoverMessageSigner([]byte(text), sig[:64]); err == nil {
+		t.Fatal("expect error of invalid length")
+	}
+}
+
+func TestMessageSigners(t *testing.T) {
+	keySigner, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("login nonce: 8f2a")
+	want, err := SignMessage(keySigner, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	account, err := ImportHexKeystoreAccount(dir, testSignerKey, "pass", LightScrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlocked, err := UnlockKeystore(account.File, "pass", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newStandInClient(t, &standInSigner{key: keySigner.key})
+	defer client.Close()
+	external := NewExternalSignerWithClient(client, keySigner.Address().String())
+
+	// signatures are deterministic, every signer of the same key signs the same
+	for _, signer := range []MessageSigner{unlocked, external} {
+		sig, err := SignMessageContext(context.Background(), signer, msg)
+		if err != nil {
+			t.Fatalf("%v: %v", signer, err)
+		}
+		if !bytes.Equal(sig, want) {
+			t.Fatalf("%v: signature %x want %x", signer, sig, want)
+		}
+	}
+
+	unlocked.Lock()
+	if _, err := SignMessage(unlocked, msg); err == nil {
+		t.Fatal("expect error of locked account")
+	}
+	tampered := newStandInClient(t, &standInSigner{key: keySigner.key, tamper: true})
+	defer tampered.Close()
+	if _, err := SignMessage(NewExternalSignerWithClient(tampered, keySigner.Address().String()), msg); err == nil {
+		t.Fatal("expect error of signature of other data")
+	}
+	if _, err := SignMessage(NewExternalSignerWithClient(client, testAddress(0xbb)), msg); err == nil {
+		t.Fatal("expect error of unknown account")
+	}
+}
@@ -0,0 +1,166 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// Multicall3Address is the address of Multicall3 on most public chains
+	Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+	// Multicall3ABI is the aggregate3 function of Multicall3
+	Multicall3ABI = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+	// MulticallBytecode creates an aggregator with aggregate3 of Multicall3 only, for chains without Multicall3.
+	// Its runtime code is compiled from multicall.easm with `evm compile multicall.easm` of go-ethereum,
+	// and prefixed with a constructor returning it: PUSH2 <size> DUP1 PUSH1 0x0c PUSH1 0 CODECOPY PUSH1 0 RETURN
+	MulticallBytecode = "0x61010280600c6000396000f360003560e01c6382ad56cb14630000001657600080fd5b6004356004018035602052602001604052602060805260205160a05260205160051b60c0016060525b602051600051101563000000f85760005160051b6040510135604051018060400135810180358082602001606051606001376000600082606051606001600087355af1806300000099578360200135630000009957600080fd5b60c06060510360005160051b60c00152606051526040606051602001523d606051604001523d60006060516060013e60003d6060516060010152601f19601f3d011660600160605101606052505050600160005101600052630000003f565b6080606051036080f3"
+
+	methodAggregate3 = "aggregate3"
+	// gas limit of deploying MulticallBytecode
+	multicallDeployGas = 2e5
+)
+
+var multicallABI, _ = abi.JSON(strings.NewReader(Multicall3ABI))
+
+// call3 is Multicall3.Call3
+type call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// call3Result is Multicall3.Result
+type call3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// SetMulticallAddress sets address of the aggregator used by Multicall, default is Multicall3Address
+func (tm *TransactionManager) SetMulticallAddress(address string) {
+	tm.multicallMu.Lock()
+	defer tm.multicallMu.Unlock()
+	tm.multicallAddress = address
+}
+
+// MulticallAddress returns address of the aggregator used by Multicall
+func (tm *TransactionManager) MulticallAddress() string {
+	tm.multicallMu.Lock()
+	defer tm.multicallMu.Unlock()
+	return tm.multicallAddressLocked()
+}
+
+func (tm *TransactionManager) multicallAddressLocked() string {
+	if tm.multicallAddress == "" {
+		return Multicall3Address
+	}
+	return tm.multicallAddress
+}
+
+// Multicall reads calls with one eth_call of aggregate3 at blockNumber, so all of them read the same block,
+// set blockNumber to nil for latest block. Results are in the order of calls, a failed call has its own Err,
+// and error is returned only when the aggregate call fails
+func (tm *TransactionManager) Multicall(calls []ContractCall, blockNumber *big.Int) ([]CallResult, error) {
+	return tm.MulticallContext(context.Background(), calls, blockNumber)
+}
+
+// MulticallContext is like Multicall but with ctx for cancellation and deadline
+func (tm *TransactionManager) MulticallContext(ctx context.Context, calls []ContractCall, blockNumber *big.Int) ([]CallResult, error) {
+	results := make([]CallResult, len(calls))
+	var (
+		packed  []call3
+		indexes []int // index of call of packed
+	)
+	abis := make(abiCache)
+	for i, call := range calls {
+		abiObj, err := abis.parse(call.ABI)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		payload, err := packABI(abiObj, call.Method, call.Args)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		packed = append(packed, call3{Target: common.HexToAddress(call.Contract), AllowFailure: true, CallData: payload})
+		indexes = append(indexes, i)
+	}
+	if len(packed) == 0 {
+		return results, nil
+	}
+
+	input, err := multicallABI.Pack(methodAggregate3, packed)
+	if err != nil {
+		return nil, fmt.Errorf("pack aggregate3 error: %s", err.Error())
+	}
+	to := common.HexToAddress(tm.MulticallAddress())
+	var output []byte
+	err = tm.retry.Do(ctx, func() (err error) {
+		output, err = tm.Client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: input}, blockNumber)
+		return
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aggregate3 error: %s", err.Error())
+	}
+	if len(output) == 0 {
+		return nil, fmt.Errorf("no multicall contract at %v", to.String())
+	}
+	var returned []call3Result
+	if err := multicallABI.UnpackIntoInterface(&returned, methodAggregate3, output); err != nil {
+		return nil, fmt.Errorf("unpack aggregate3 error: %s", err.Error())
+	}
+	if len(returned) != len(packed) {
+		return nil, fmt.Errorf("aggregate3 returned %v results of %v calls", len(returned), len(packed))
+	}
+
+	for j, i := range indexes {
+		r := returned[j]
+		if !r.Success {
+			results[i].Err = &RevertError{Reason: DecodeRevertReason(r.ReturnData, calls[i].ABI), Data: r.ReturnData}
+			continue
+		}
+		results[i].Output = r.ReturnData
+		abiObj, _ := abis.parse(calls[i].ABI)
+		results[i].Values, results[i].Err = unpackABI(abiObj, calls[i].Method, r.ReturnData)
+	}
+	return results, nil
+}
+
+// EnsureMulticall deploys MulticallBytecode with signer when there is no contract at tm.MulticallAddress(),
+// and uses the deployed one for Multicall. It returns address of the aggregator.
+// NOTE: the address is kept in tm only, callers should persist it and pass it to SetMulticallAddress
+// when they make a new TransactionManager, otherwise every new process deploys another aggregator
+func (tm *TransactionManager) EnsureMulticall(signer Signer) (string, error) {
+	return tm.EnsureMulticallContext(context.Background(), signer)
+}
+
+// EnsureMulticallContext is like EnsureMulticall but with ctx for cancellation and deadline
+func (tm *TransactionManager) EnsureMulticallContext(ctx context.Context, signer Signer) (string, error) {
+	// held until deployed, so concurrent calls deploy one aggregator
+	tm.multicallMu.Lock()
+	defer tm.multicallMu.Unlock()
+
+	address := tm.multicallAddressLocked()
+	code, err := tm.Client.CodeAt(ctx, common.HexToAddress(address), nil)
+	if err != nil {
+		return "", fmt.Errorf("CodeAt() error: %s", err.Error())
+	}
+	if len(code) > 0 {
+		return address, nil
+	}
+
+	address, _, _, err = tm.CreateContractSyncContext(ctx, signer, common.FromHex(MulticallBytecode), nil, 0, multicallDeployGas)
+	if err != nil {
+		return "", fmt.Errorf("deploy multicall contract error: %s", err.Error())
+	}
+	tm.multicallAddress = address
+	return address, nil
+}
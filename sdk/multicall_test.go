@@ -0,0 +1,164 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/asm"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+)
+
+// balanceOfBytecode creates a contract whose every function returns its first argument, and reverts when it's 0xff
+const balanceOfBytecode = "0x61001880600c6000396000f36004358060ff146100135760005260206000f35b600080fd"
+
+// testEVM is an in-memory EVM, eth_call and eth_getCode of a testBackend are served by it after serve
+type testEVM struct {
+	cfg *runtime.Config
+}
+
+func newTestEVM(t *testing.T) *testEVM {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testEVM{cfg: &runtime.Config{State: statedb, BlockNumber: big.NewInt(1)}}
+}
+
+func (e *testEVM) deploy(t *testing.T, bytecode string) common.Address {
+	_, address, _, err := runtime.Create(common.FromHex(bytecode), e.cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+// serve answers eth_call and eth_getCode of backend with e, they run with lock of backend held
+func (e *testEVM) serve(backend *testBackend) {
+	backend.set(func(b *testBackend) {
+		b.call = func(args testCallArgs, block string) ([]byte, error) {
+			output, _, err := runtime.Call(*args.To, args.Data, e.cfg)
+			return output, err
+		}
+		b.code = func(address common.Address) []byte {
+			return e.cfg.State.GetCode(address)
+		}
+	})
+}
+
+func TestMulticall(t *testing.T) {
+	evm := newTestEVM(t)
+	aggregator := evm.deploy(t, MulticallBytecode)
+	token := evm.deploy(t, balanceOfBytecode)
+
+	backend := newTestBackend(t)
+	defer backend.close()
+	evm.serve(backend)
+	tm, closeFn := backend.newTM(t)
+	defer closeFn()
+
+	// code of the default address is missing
+	if _, err := tm.Multicall([]ContractCall{{Contract: token.String(), ABI: ERC20_ABI, Method: MethodTotalSupply}}, nil); err == nil {
+		t.Fatal("expect error of missing multicall contract")
+	}
+	// EnsureMulticall doesn't deploy when it exists
+	tm.SetMulticallAddress(aggregator.String())
+	address, err := tm.EnsureMulticall(nil)
+	if err != nil || address != aggregator.String() {
+		t.Fatalf("ensure: %v %v", address, err)
+	}
+
+	var calls []ContractCall
+	for i := 1; i <= 20; i++ {
+		calls = append(calls, ContractCall{Contract: token.String(), ABI: ERC20_ABI, Method: MethodBalanceOf, Args: "address:" + testAddress(byte(i))})
+	}
+	calls = append(calls,
+		ContractCall{Contract: token.String(), ABI: ERC20_ABI, Method: MethodBalanceOf, Args: "address:" + testAddress(0xff)},
+		ContractCall{Contract: token.String(), ABI: ERC20_ABI, Method: MethodBalanceOf, Args: "uint256:abc"},
+		// no code
+		ContractCall{Contract: testAddress(0xee), ABI: ERC20_ABI, Method: MethodBalanceOf, Args: "address:" + testAddress(1)},
+	)
+	results, err := tm.Multicall(calls, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(calls) {
+		t.Fatalf("%v results", len(results))
+	}
+	for i := 0; i < 20; i++ {
+		r := results[i]
+		if r.Err != nil || len(r.Output) != 32 || r.Values[0].(*big.Int).Int64() != int64(i+1) {
+			t.Fatalf("result %v: %+v", i, r)
+		}
+	}
+	if !errors.Is(results[20].Err, ErrReverted) {
+		t.Fatalf("reverted call: %+v", results[20])
+	}
+	var argErr *ArgError
+	if !errors.As(results[21].Err, &argErr) {
+		t.Fatalf("pack error: %+v", results[21])
+	}
+	if !errors.Is(results[22].Err, ErrUnexpectedReturn) || len(results[22].Output) != 0 {
+		t.Fatalf("call without code: %+v", results[22])
+	}
+}
+
+// TestMulticallSource checks MulticallBytecode is built from multicall.easm
+func TestMulticallSource(t *testing.T) {
+	src, err := ioutil.ReadFile("multicall.easm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compiler := asm.NewCompiler(false)
+	compiler.Feed(asm.Lex(src, false))
+	code, errs := compiler.Compile()
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	creation := fmt.Sprintf("0x61%04x80600c6000396000f3%s", len(code)/2, code)
+	if creation != MulticallBytecode {
+		t.Fatalf("MulticallBytecode is not built from multicall.easm, want %v", creation)
+	}
+}
+
+func TestMulticallBytecode(t *testing.T) {
+	evm := newTestEVM(t)
+	aggregator := evm.deploy(t, MulticallBytecode)
+	token := evm.deploy(t, balanceOfBytecode)
+
+	// a failed call with allowFailure false reverts the aggregate call
+	input, err := multicallABI.Pack(methodAggregate3, []call3{
+		{Target: token, AllowFailure: true, CallData: common.FromHex("0x70a08231" + "00000000000000000000000000000000000000000000000000000000000000ff")},
+		{Target: token, AllowFailure: false, CallData: common.FromHex("0x70a08231" + "00000000000000000000000000000000000000000000000000000000000000ff")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := runtime.Call(aggregator, input, evm.cfg); err == nil {
+		t.Fatal("expect revert")
+	}
+
+	// unknown selector
+	if _, _, err := runtime.Call(aggregator, []byte{1, 2, 3, 4}, evm.cfg); err == nil {
+		t.Fatal("expect revert of unknown selector")
+	}
+
+	// empty calls
+	input, err = multicallABI.Pack(methodAggregate3, []call3{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, _, err := runtime.Call(aggregator, input, evm.cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var returned []call3Result
+	if err := multicallABI.UnpackIntoInterface(&returned, methodAggregate3, output); err != nil || len(returned) != 0 {
+		t.Fatalf("empty calls: %v %v", returned, err)
+	}
+}
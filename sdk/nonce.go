@@ -0,0 +1,173 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// resync nonce of a sender from chain when it has been idle for this long
+	nonceIdleResync = 30 * time.Second
+)
+
+// NonceSource returns the next nonce of account including pending transactions, *ethclient.Client implements it
+type NonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceManager hands out nonces per sender address locally,
+// so concurrent senders with the same key don't get duplicate nonces
+// a nonce handed out by Acquire is outstanding until it's given back by Release or Commit
+type NonceManager struct {
+	source NonceSource
+
+	mu      sync.Mutex
+	senders map[common.Address]*senderNonce
+}
+
+type senderNonce struct {
+	mu       sync.Mutex
+	synced   bool
+	next     uint64          // lowest nonce never handed out
+	released []uint64        // nonces handed out then released, sorted, reused first
+	acquired map[uint64]bool // outstanding nonces, handed out and not released or committed
+	lastUsed time.Time
+}
+
+// NewNonceManager makes a NonceManager which syncs nonces from source
+func NewNonceManager(source NonceSource) *NonceManager {
+	return &NonceManager{
+		source:  source,
+		senders: make(map[common.Address]*senderNonce),
+	}
+}
+
+func (nm *NonceManager) sender(account common.Address) *senderNonce {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	s, ok := nm.senders[account]
+	if !ok {
+		s = &senderNonce{acquired: make(map[uint64]bool)}
+		nm.senders[account] = s
+	}
+	return s
+}
+
+// sync sets next nonce of s to pending nonce of chain, caller must hold s.mu
+// while nonces are outstanding next is only raised, lowering it would hand them out twice
+func (nm *NonceManager) sync(ctx context.Context, account common.Address, s *senderNonce) error {
+	nonce, err := nm.source.PendingNonceAt(ctx, account)
+	if err != nil {
+		return fmt.Errorf("PendingNonceAt() error: %s", err.Error())
+	}
+	if s.synced && len(s.acquired) > 0 {
+		if nonce > s.next {
+			s.next = nonce
+		}
+		// released nonces below chain nonce are used by transactions sent by others
+		for len(s.released) > 0 && s.released[0] < nonce {
+			s.released = s.released[1:]
+		}
+		return nil
+	}
+	if s.synced && nonce != s.next {
+		logrus.Warnf("nonce gap of %v: local next nonce %v, chain pending nonce %v", account.String(), s.next, nonce)
+	}
+	s.next = nonce
+	s.released = nil
+	s.synced = true
+	return nil
+}
+
+// Acquire hands out the next nonce of account, released nonces are reused first
+// nonce of account is synced from chain at first use and after it has been idle for a while
+func (nm *NonceManager) Acquire(ctx context.Context, account common.Address) (uint64, error) {
+	s := nm.sender(account)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.synced || time.Since(s.lastUsed) > nonceIdleResync {
+		if err := nm.sync(ctx, account, s); err != nil {
+			return 0, err
+		}
+	}
+	s.lastUsed = time.Now()
+
+	var nonce uint64
+	if len(s.released) > 0 {
+		nonce = s.released[0]
+		s.released = s.released[1:]
+	} else {
+		nonce = s.next
+		s.next++
+	}
+	s.acquired[nonce] = true
+	return nonce, nil
+}
+
+// Release gives back nonce of account which is not broadcast, it will be handed out again
+// only release a nonce whose tx is never sent or definitely refused by node, otherwise Commit it
+func (nm *NonceManager) Release(account common.Address, nonce uint64) {
+	s := nm.sender(account)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.acquired, nonce)
+	if !s.synced || nonce >= s.next {
+		return
+	}
+	for _, n := range s.released {
+		if n == nonce {
+			return
+		}
+	}
+	s.released = append(s.released, nonce)
+	sort.Slice(s.released, func(i, j int) bool { return s.released[i] < s.released[j] })
+
+	// shrink next when the highest nonces are released
+	for len(s.released) > 0 && s.released[len(s.released)-1] == s.next-1 {
+		s.released = s.released[:len(s.released)-1]
+		s.next--
+	}
+}
+
+// Commit tells nonce of account is broadcast, or may be, so it's not outstanding anymore
+func (nm *NonceManager) Commit(account common.Address, nonce uint64) {
+	s := nm.sender(account)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.acquired, nonce)
+}
+
+// Resync syncs local nonce state of account from chain,
+// while nonces of account are outstanding it only raises the next nonce
+func (nm *NonceManager) Resync(ctx context.Context, account common.Address) error {
+	s := nm.sender(account)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return nm.sync(ctx, account, s)
+}
+
+// isRejected reports whether err of sending a tx is node's refusal of it, so its nonce is not used,
+// errors without response of node, such as timeout, may come after the tx has reached the node
+func isRejected(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && !isAlreadyKnown(err)
+}
+
+// isNonceTooLow reports whether err is node's 'nonce too low' error
+func isNonceTooLow(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "nonce too low")
+}
@@ -0,0 +1,212 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeNonceSource returns nonce, moved past the nonces sent to it
+type fakeNonceSource struct {
+	mu    sync.Mutex
+	nonce uint64
+	sent  map[uint64]bool
+}
+
+func (f *fakeNonceSource) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for f.sent[f.nonce] {
+		f.nonce++
+	}
+	return f.nonce, nil
+}
+
+func (f *fakeNonceSource) send(nonce uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent[nonce] = true
+}
+
+func TestNonceManagerConcurrent(t *testing.T) {
+	nm := NewNonceManager(&fakeNonceSource{nonce: 5})
+	account := common.HexToAddress(addr1)
+
+	const count = 100
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		nonces = make(map[uint64]bool)
+	)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := nm.Acquire(context.Background(), account)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			nonces[nonce] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for n := uint64(5); n < 5+count; n++ {
+		if !nonces[n] {
+			t.Fatalf("nonce %v not handed out", n)
+		}
+	}
+}
+
+func TestNonceManagerRelease(t *testing.T) {
+	source := &fakeNonceSource{nonce: 3}
+	nm := NewNonceManager(source)
+	account := common.HexToAddress(addr1)
+	ctx := context.Background()
+
+	for want := uint64(3); want < 6; want++ {
+		if n, _ := nm.Acquire(ctx, account); n != want {
+			t.Fatalf("acquire nonce: got %v want %v", n, want)
+		}
+	}
+	// release a nonce in the middle, it's reused first
+	nm.Release(account, 4)
+	if n, _ := nm.Acquire(ctx, account); n != 4 {
+		t.Fatalf("reuse released nonce: got %v want 4", n)
+	}
+	// release the highest nonce, next nonce shrinks
+	nm.Release(account, 5)
+	if n, _ := nm.Acquire(ctx, account); n != 5 {
+		t.Fatalf("acquire after release highest: got %v want 5", n)
+	}
+
+	// chain moved on, resync picks it up
+	source.nonce = 10
+	if err := nm.Resync(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := nm.Acquire(ctx, account); n != 10 {
+		t.Fatalf("acquire after resync: got %v want 10", n)
+	}
+}
+
+func TestNonceManagerResyncConcurrent(t *testing.T) {
+	source := &fakeNonceSource{nonce: 5, sent: make(map[uint64]bool)}
+	nm := NewNonceManager(source)
+	account := common.HexToAddress(addr1)
+	ctx := context.Background()
+
+	const count = 100
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		nonces = make(map[uint64]bool)
+	)
+	for i := 0; i < count; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			nonce, err := nm.Acquire(ctx, account)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			if nonces[nonce] {
+				t.Errorf("nonce %v is handed out twice", nonce)
+			}
+			nonces[nonce] = true
+			mu.Unlock()
+			// signing and sending, chain doesn't see the nonce until it's sent
+			time.Sleep(time.Millisecond)
+			source.send(nonce)
+			nm.Commit(account, nonce)
+		}()
+		// resync while other nonces are outstanding
+		go func() {
+			defer wg.Done()
+			if err := nm.Resync(ctx, account); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for n := uint64(5); n < 5+count; n++ {
+		if !nonces[n] {
+			t.Fatalf("nonce %v not handed out", n)
+		}
+	}
+	if n, _ := nm.Acquire(ctx, account); n != 5+count {
+		t.Fatalf("acquire after resync: got %v want %v", n, 5+count)
+	}
+}
+
+type testRPCError string
+
+func (e testRPCError) Error() string  { return string(e) }
+func (e testRPCError) ErrorCode() int { return -32000 }
+
+func TestIsRejected(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{testRPCError("nonce too low"), true},
+		{classifyNodeError(fmt.Errorf("send error: %w", testRPCError("insufficient funds for gas * price + value"))), true},
+		{testRPCError("invalid sender"), true},
+		{testRPCError("already known"), false},
+		{context.DeadlineExceeded, false},
+		{rpc.HTTPError{StatusCode: 502, Status: "502 Bad Gateway"}, false},
+		{errors.New("connection reset by peer"), false},
+	}
+	for _, c := range cases {
+		if got := isRejected(c.err); got != c.want {
+			t.Errorf("%v: got %v", c.err, got)
+		}
+	}
+}
+
+func TestSendTxNonce(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.close()
+	tm, closeFn := backend.newTM(t)
+	defer closeFn()
+	tm.EnableNonceManager()
+	signer := hexSigner(t, testSignerKey)
+
+	// refused by node, the nonce is handed out again
+	backend.set(func(b *testBackend) {
+		b.sendErr = func(tx *types.Transaction) error { return errors.New("insufficient funds for gas * price + value") }
+	})
+	if _, err := tm.SendTx(signer, testAddress(1), big.NewInt(1), nil, nil, 0, 0); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expect ErrInsufficientFunds, got %v", err)
+	}
+	backend.set(func(b *testBackend) { b.sendErr = nil })
+	if _, err := tm.SendTx(signer, testAddress(1), big.NewInt(1), nil, nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if nonce := backend.lastSent(t).Nonce(); nonce != 0 {
+		t.Fatalf("nonce %v", nonce)
+	}
+
+	// no response of node, the tx may be sent so its nonce is not handed out again
+	backend.http.Close()
+	if _, err := tm.SendTx(signer, testAddress(1), big.NewInt(1), nil, nil, 0, 0); err == nil {
+		t.Fatal("expect error of closed node")
+	}
+	if nonce, _ := tm.NonceManager().Acquire(context.Background(), signer.Address()); nonce != 2 {
+		t.Fatalf("next nonce %v", nonce)
+	}
+}
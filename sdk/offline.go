@@ -0,0 +1,197 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxParams are all fields of a transaction built offline, nothing is fetched from node
+// the type is decided by fee fields:
+// GasTipCap and GasFeeCap for EIP-1559 transaction,
+// GasPrice with AccessList for EIP-2930 transaction, GasPrice only for legacy transaction
+type TxParams struct {
+	ChainID  *big.Int // required by EIP-155 and typed transactions, nil makes legacy tx without replay protection
+	Nonce    uint64
+	To       string // empty to create contract
+	Value    *big.Int
+	Data     []byte
+	GasLimit uint64
+
+	GasPrice   *big.Int
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	AccessList types.AccessList
+}
+
+// SignedTx is a signed transaction to broadcast later with BroadcastRaw
+type SignedTx struct {
+	Raw  string // 0x hex of binary encoding: RLP for legacy tx, type byte + RLP for typed tx
+	Hash string
+	Tx   *types.Transaction
+}
+
+// BuildTx makes unsigned transaction of params
+func BuildTx(params TxParams) (*types.Transaction, error) {
+	if params.GasLimit == 0 {
+		return nil, fmt.Errorf("gas limit is required")
+	}
+	var to *common.Address
+	if params.To != "" {
+		if !common.IsHexAddress(params.To) {
+			return nil, fmt.Errorf("invalid to address %v", params.To)
+		}
+		address := common.HexToAddress(params.To)
+		to = &address
+	}
+	value := params.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	switch {
+	case params.GasTipCap != nil || params.GasFeeCap != nil:
+		if params.GasTipCap == nil || params.GasFeeCap == nil {
+			return nil, fmt.Errorf("EIP-1559 transaction requires both gas tip cap and gas fee cap")
+		}
+		if params.GasPrice != nil {
+			return nil, fmt.Errorf("gas price is not used by EIP-1559 transaction")
+		}
+		if params.GasTipCap.Cmp(params.GasFeeCap) > 0 {
+			return nil, fmt.Errorf("gas tip cap %v is higher than gas fee cap %v", params.GasTipCap, params.GasFeeCap)
+		}
+		if params.ChainID == nil {
+			return nil, fmt.Errorf("EIP-1559 transaction requires chain id")
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    params.ChainID,
+			Nonce:      params.Nonce,
+			GasTipCap:  params.GasTipCap,
+			GasFeeCap:  params.GasFeeCap,
+			Gas:        params.GasLimit,
+			To:         to,
+			Value:      value,
+			Data:       params.Data,
+			AccessList: params.AccessList,
+		}), nil
+	case params.GasPrice == nil:
+		return nil, fmt.Errorf("gas price is required")
+	case params.AccessList != nil:
+		if params.ChainID == nil {
+			return nil, fmt.Errorf("EIP-2930 transaction requires chain id")
+		}
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    params.ChainID,
+			Nonce:      params.Nonce,
+			GasPrice:   params.GasPrice,
+			Gas:        params.GasLimit,
+			To:         to,
+			Value:      value,
+			Data:       params.Data,
+			AccessList: params.AccessList,
+		}), nil
+	default:
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    params.Nonce,
+			GasPrice: params.GasPrice,
+			Gas:      params.GasLimit,
+			To:       to,
+			Value:    value,
+			Data:     params.Data,
+		}), nil
+	}
+}
+
+// offlineTxSigner returns types.Signer of chainID, nil chainID is for legacy tx without EIP-155
+func offlineTxSigner(chainID *big.Int) types.Signer {
+	if chainID == nil {
+		return types.HomesteadSigner{}
+	}
+	return types.LatestSignerForChainID(chainID)
+}
+
+// recoverSender returns sender of signed tx, which is legacy tx with or without EIP-155, or typed tx
+func recoverSender(tx *types.Transaction) (common.Address, error) {
+	var chainID *big.Int
+	if tx.Protected() {
+		chainID = tx.ChainId()
+	}
+	return types.Sender(offlineTxSigner(chainID), tx)
+}
+
+// BuildSignedTx builds transaction of params and signs it with signer without RPC connection
+func BuildSignedTx(signer Signer, params TxParams) (*SignedTx, error) {
+	return BuildSignedTxContext(context.Background(), signer, params)
+}
+
+// BuildSignedTxContext is like BuildSignedTx but with ctx for cancellation and deadline of signer
+func BuildSignedTxContext(ctx context.Context, signer Signer, params TxParams) (*SignedTx, error) {
+	tx, err := BuildTx(params)
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := signer.SignTx(ctx, offlineTxSigner(params.ChainID), tx)
+	if err != nil {
+		return nil, fmt.Errorf("sign tx error: %s", err.Error())
+	}
+	raw, err := signedTx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &SignedTx{Raw: hexutil.Encode(raw), Hash: signedTx.Hash().String(), Tx: signedTx}, nil
+}
+
+// BroadcastRaw sends raw signed transaction made by BuildSignedTx, returns its hash
+// the transaction is checked to be of the chain of tm before sending
+func (tm *TransactionManager) BroadcastRaw(raw string) (string, error) {
+	return tm.BroadcastRawContext(context.Background(), raw)
+}
+
+// BroadcastRawContext is like BroadcastRaw but with ctx for cancellation and deadline
+func (tm *TransactionManager) BroadcastRawContext(ctx context.Context, raw string) (string, error) {
+	data, err := hexutil.Decode(raw)
+	if err != nil {
+		return "", fmt.Errorf("decode raw transaction error: %s", err.Error())
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return "", fmt.Errorf("decode raw transaction error: %s", err.Error())
+	}
+	if tx.Protected() && tx.ChainId().String() != tm.chainID {
+		return "", fmt.Errorf("transaction of chain %v is not for chain %v", tx.ChainId(), tm.chainID)
+	}
+
+	from, err := recoverSender(tx)
+	if err != nil {
+		return "", fmt.Errorf("recover sender error: %s", err.Error())
+	}
+
+	if err := tm.broadcast(ctx, from, tx); err != nil {
+		return tx.Hash().String(), err
+	}
+	return tx.Hash().String(), nil
+}
+
+// BroadcastRawSync sends raw signed transaction and waits its receipt, return hash, gas used, error
+// error is *RevertError with hash and gas used when tx is reverted
+func (tm *TransactionManager) BroadcastRawSync(raw string) (string, uint64, error) {
+	return tm.BroadcastRawSyncContext(context.Background(), raw)
+}
+
+// BroadcastRawSyncContext is like BroadcastRawSync but with ctx for cancellation and deadline
+func (tm *TransactionManager) BroadcastRawSyncContext(ctx context.Context, raw string) (string, uint64, error) {
+	hash, err := tm.BroadcastRawContext(ctx, raw)
+	if err != nil {
+		return "", 0, err
+	}
+
+	receipt, err := tm.waitSuccess(ctx, hash, "")
+	if receipt == nil {
+		return "", 0, err
+	}
+	return hash, receipt.GasUsed, err
+}
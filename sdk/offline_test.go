@@ -0,0 +1,128 @@
+package sdk
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestBuildSignedTx(t *testing.T) {
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(5)
+	to := "0x00000000000000000000000000000000000000aa"
+	accessList := types.AccessList{{Address: common.HexToAddress(to), StorageKeys: []common.Hash{{1}}}}
+
+	cases := []struct {
+		name      string
+		params    TxParams
+		txType    uint8
+		protected bool
+	}{
+		{"legacy", TxParams{ChainID: chainID, Nonce: 1, To: to, Value: big.NewInt(1), GasLimit: 21000, GasPrice: big.NewInt(1e9)}, types.LegacyTxType, true},
+		{"legacy without EIP-155", TxParams{Nonce: 2, To: to, GasLimit: 21000, GasPrice: big.NewInt(1e9)}, types.LegacyTxType, false},
+		{"EIP-2930", TxParams{ChainID: chainID, Nonce: 3, To: to, GasLimit: 30000, GasPrice: big.NewInt(1e9), AccessList: accessList}, types.AccessListTxType, true},
+		{"EIP-1559", TxParams{ChainID: chainID, Nonce: 4, To: to, GasLimit: 21000, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(3e9)}, types.DynamicFeeTxType, true},
+		{"create", TxParams{ChainID: chainID, Nonce: 5, GasLimit: 100000, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(3e9), Data: []byte{0x60, 0x00}}, types.DynamicFeeTxType, true},
+	}
+	for _, c := range cases {
+		signed, err := BuildSignedTx(signer, c.params)
+		if err != nil {
+			t.Fatalf("%v: %v", c.name, err)
+		}
+		raw, err := hexutil.Decode(signed.Raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			t.Fatalf("%v: %v", c.name, err)
+		}
+		if tx.Hash().String() != signed.Hash || tx.Type() != c.txType || tx.Protected() != c.protected || tx.Nonce() != c.params.Nonce {
+			t.Fatalf("%v: hash %v type %v protected %v", c.name, tx.Hash().String(), tx.Type(), tx.Protected())
+		}
+		sender, err := types.Sender(offlineTxSigner(c.params.ChainID), tx)
+		if err != nil || sender != signer.Address() {
+			t.Fatalf("%v: sender %v %v", c.name, sender.String(), err)
+		}
+		if (c.params.To == "") != (tx.To() == nil) {
+			t.Fatalf("%v: to %v", c.name, tx.To())
+		}
+	}
+
+	invalid := []TxParams{
+		{ChainID: chainID, To: to, GasPrice: big.NewInt(1)},
+		{ChainID: chainID, To: to, GasLimit: 21000},
+		{ChainID: chainID, To: "0x1234", GasLimit: 21000, GasPrice: big.NewInt(1)},
+		{ChainID: chainID, To: to, GasLimit: 21000, GasTipCap: big.NewInt(1)},
+		{ChainID: chainID, To: to, GasLimit: 21000, GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(1)},
+		{To: to, GasLimit: 21000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1)},
+		{To: to, GasLimit: 21000, GasPrice: big.NewInt(1), AccessList: accessList},
+	}
+	for i, params := range invalid {
+		if _, err := BuildSignedTx(signer, params); err == nil {
+			t.Errorf("invalid params %v: expect error", i)
+		}
+	}
+}
+
+func TestBroadcastRaw(t *testing.T) {
+	node := newTestBackend(t)
+	defer node.close()
+	client, err := rpc.DialHTTP(node.http.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	tm := &TransactionManager{rpcClient: client, Client: ethclient.NewClient(client), chainID: "5"}
+
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := TxParams{ChainID: big.NewInt(5), Nonce: 1, To: "0x00000000000000000000000000000000000000aa", GasLimit: 21000, GasPrice: big.NewInt(1e9)}
+	signed, err := BuildSignedTx(signer, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := tm.BroadcastRaw(signed.Raw)
+	if err != nil || hash != signed.Hash {
+		t.Fatalf("broadcast: %v %v", hash, err)
+	}
+	if node.count("eth_sendRawTransaction") != 1 || tm.sent.get(common.HexToHash(hash)) == nil {
+		t.Fatal("transaction is not sent")
+	}
+
+	// other chain
+	params.ChainID = big.NewInt(1)
+	signed, err = BuildSignedTx(signer, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.BroadcastRaw(signed.Raw); err == nil {
+		t.Fatal("expect error of chain id")
+	}
+	if _, err := tm.BroadcastRaw("0x1234"); err == nil {
+		t.Fatal("expect error of invalid raw transaction")
+	}
+
+	node.set(func(n *testBackend) {
+		n.sendErr = func(tx *types.Transaction) error { return errors.New("nonce too low") }
+	})
+	params.ChainID = big.NewInt(5)
+	signed, err = BuildSignedTx(signer, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.BroadcastRaw(signed.Raw); !errors.Is(err, ErrNonceTooLow) {
+		t.Fatalf("expect ErrNonceTooLow, got %v", err)
+	}
+}
@@ -0,0 +1,385 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultQueueWorkers     = 4
+	defaultQueueMaxInFlight = 16
+	defaultQueueMaxPending  = 256
+)
+
+// TxJob is a transaction submitted to TxQueue, fields are the same as arguments of SendTx
+// its nonce is handed out by the queue in the order of submitting
+type TxJob struct {
+	Signer   Signer
+	To       string
+	Value    *big.Int
+	Data     []byte
+	GasPrice *big.Int // nil for price of gas price strategy
+	GasLimit uint64   // 0 for gas limit of SendTx, or of TransferEth when there is no data
+	ABI      string   // decodes custom errors of revert, optional
+	Label    string   // written to journal, and reported in TxJobEvent
+}
+
+// TransferEthJob makes job of transferring value wei to to,
+// its gas limit is decided when it's sent as TransferEth does
+func TransferEthJob(signer Signer, to string, value *big.Int) TxJob {
+	return TxJob{Signer: signer, To: to, Value: value}
+}
+
+// Transfer20Job makes job of ERC20 transfer, value is in the smallest unit of token
+func Transfer20Job(signer Signer, contractAddress string, to string, value string) (TxJob, error) {
+	data, err := Pack(ERC20_ABI, MethodTransfer, fmt.Sprintf("address:%v;uint256:%v", to, value))
+	if err != nil {
+		return TxJob{}, err
+	}
+	return TxJob{Signer: signer, To: contractAddress, Data: data, ABI: ERC20_ABI}, nil
+}
+
+// TxJobError is the error of a failed job, Hash is empty when the tx is not sent
+// Err is matched by errors.Is and errors.As, e.g. ErrInsufficientFunds, ErrTimeout, *RevertError
+type TxJobError struct {
+	ID    uint64
+	Label string
+	Hash  string
+	Err   error
+}
+
+func (e *TxJobError) Error() string {
+	msg := fmt.Sprintf("job %v", e.ID)
+	if e.Label != "" {
+		msg += fmt.Sprintf(" (%v)", e.Label)
+	}
+	return msg + " error: " + e.Err.Error()
+}
+
+func (e *TxJobError) Unwrap() error {
+	return e.Err
+}
+
+// TxJobEvent reports a finished job, Err is *TxJobError when it failed
+type TxJobEvent struct {
+	ID      uint64
+	Label   string
+	From    common.Address
+	Hash    string
+	Receipt *types.Receipt
+	Err     error
+}
+
+// TxFuture is the handle of a submitted job
+type TxFuture struct {
+	id   uint64
+	job  TxJob
+	lane *txLane
+	done chan struct{}
+
+	mu      sync.Mutex
+	hash    string
+	receipt *types.Receipt
+	err     error
+}
+
+// ID returns id of the job, in the order of submitting
+func (f *TxFuture) ID() uint64 {
+	return f.id
+}
+
+// Hash returns hash of the tx, empty before it's sent
+func (f *TxFuture) Hash() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.hash
+}
+
+// Done is closed when the job is finished
+func (f *TxFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the job is finished or ctx is done, returns receipt of the tx mined with tm.confirmations
+// error is *TxJobError when the job failed
+func (f *TxFuture) Wait(ctx context.Context) (*types.Receipt, error) {
+	select {
+	case <-f.done:
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return f.receipt, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// txLane is the jobs of a sender, they are sent one by one so nonces are in order
+// it's dropped when it's idle, so senders seen once don't pile up
+type txLane struct {
+	from       common.Address
+	jobs       []*TxFuture   // waiting to be sent
+	inFlight   int           // sent and not finished
+	busy       bool          // in ready list or being sent by a worker
+	submitting int           // Submit calls got the lane and haven't enqueued their jobs
+	slots      chan struct{} // one for every pending (waiting or in flight) job
+}
+
+// TxQueueConfig configures TxQueue, zero fields use defaults
+type TxQueueConfig struct {
+	Workers     int // transactions signed and sent at the same time, default 4
+	MaxInFlight int // sent transactions of a sender not mined yet, default 16
+	MaxPending  int // waiting and in flight jobs of a sender, Submit blocks when it's reached, default 256
+}
+
+// TxQueue sends submitted jobs with a pool of workers,
+// jobs of the same sender are sent one by one in the order of submitting, so their nonces are in order,
+// jobs of different senders are sent concurrently
+type TxQueue struct {
+	tm  *TransactionManager
+	cfg TxQueueConfig
+
+	mu      sync.Mutex
+	cond    *sync.Cond // signals workers when ready gets a lane or queue is stopped
+	lanes   map[common.Address]*txLane
+	ready   []*txLane
+	nextID  uint64
+	closed  bool // no more jobs are accepted
+	stopped bool // workers exit
+
+	jobs    sync.WaitGroup // unfinished jobs
+	workers sync.WaitGroup
+	feed    event.Feed // TxJobEvent
+}
+
+// NewTxQueue makes a TxQueue sending with tm, and starts its workers
+// nonces are handed out locally in the order of jobs, so nonce manager of tm must be enabled by EnableNonceManager
+func (tm *TransactionManager) NewTxQueue(cfg TxQueueConfig) (*TxQueue, error) {
+	if tm.nonceManager == nil {
+		return nil, fmt.Errorf("nonce manager is not enabled, call EnableNonceManager first")
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultQueueWorkers
+	}
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = defaultQueueMaxInFlight
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = defaultQueueMaxPending
+	}
+	q := &TxQueue{tm: tm, cfg: cfg, lanes: make(map[common.Address]*txLane)}
+	q.cond = sync.NewCond(&q.mu)
+	q.workers.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go q.work()
+	}
+	return q, nil
+}
+
+// Subscribe subscribes events of finished jobs
+// events are sent synchronously, a slow subscriber delays the queue, so ch should be buffered and drained
+func (q *TxQueue) Subscribe(ch chan<- TxJobEvent) event.Subscription {
+	return q.feed.Subscribe(ch)
+}
+
+// Submit adds job to the queue, it blocks while the sender has cfg.MaxPending jobs, until ctx is done
+func (q *TxQueue) Submit(ctx context.Context, job TxJob) (*TxFuture, error) {
+	lane, err := q.lane(job)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case lane.slots <- struct{}{}:
+	case <-ctx.Done():
+		q.leave(lane)
+		return nil, ctx.Err()
+	}
+	return q.enqueue(lane, job)
+}
+
+// TrySubmit is like Submit but returns ErrQueueFull instead of blocking
+func (q *TxQueue) TrySubmit(job TxJob) (*TxFuture, error) {
+	lane, err := q.lane(job)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case lane.slots <- struct{}{}:
+	default:
+		q.leave(lane)
+		return nil, ErrQueueFull
+	}
+	return q.enqueue(lane, job)
+}
+
+// lane returns lane of sender of job, it's kept until enqueue or leave
+func (q *TxQueue) lane(job TxJob) (*txLane, error) {
+	if job.Signer == nil {
+		return nil, fmt.Errorf("signer of job is required")
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+	from := job.Signer.Address()
+	lane, ok := q.lanes[from]
+	if !ok {
+		lane = &txLane{from: from, slots: make(chan struct{}, q.cfg.MaxPending)}
+		q.lanes[from] = lane
+	}
+	lane.submitting++
+	return lane, nil
+}
+
+// leave gives up lane got by lane without enqueuing a job
+func (q *TxQueue) leave(lane *txLane) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	lane.submitting--
+	q.prune(lane)
+}
+
+// prune drops lane when it has no jobs, caller must hold q.mu
+func (q *TxQueue) prune(lane *txLane) {
+	if lane.busy || lane.submitting > 0 || len(lane.jobs) > 0 || lane.inFlight > 0 {
+		return
+	}
+	if q.lanes[lane.from] == lane {
+		delete(q.lanes, lane.from)
+	}
+}
+
+// enqueue appends job to lane holding a slot of it
+func (q *TxQueue) enqueue(lane *txLane, job TxJob) (*TxFuture, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	lane.submitting--
+	if q.closed {
+		<-lane.slots
+		q.prune(lane)
+		return nil, ErrQueueClosed
+	}
+	q.nextID++
+	f := &TxFuture{id: q.nextID, job: job, lane: lane, done: make(chan struct{})}
+	q.jobs.Add(1)
+	lane.jobs = append(lane.jobs, f)
+	q.schedule(lane)
+	return f, nil
+}
+
+// schedule puts lane to ready list when it has a job to send and room of in flight, caller must hold q.mu
+func (q *TxQueue) schedule(lane *txLane) {
+	if lane.busy || len(lane.jobs) == 0 || lane.inFlight >= q.cfg.MaxInFlight {
+		return
+	}
+	lane.busy = true
+	q.ready = append(q.ready, lane)
+	q.cond.Signal()
+}
+
+// work sends the first job of ready lanes until the queue is stopped
+func (q *TxQueue) work() {
+	defer q.workers.Done()
+	for {
+		q.mu.Lock()
+		for len(q.ready) == 0 && !q.stopped {
+			q.cond.Wait()
+		}
+		if len(q.ready) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		lane := q.ready[0]
+		q.ready = q.ready[1:]
+		f := lane.jobs[0]
+		lane.jobs = lane.jobs[1:]
+		lane.inFlight++
+		q.mu.Unlock()
+
+		job := f.job
+		ctx := context.Background()
+		if job.Label != "" {
+			ctx = WithJournalLabel(ctx, job.Label)
+		}
+		gasLimit := job.GasLimit
+		if gasLimit == 0 {
+			gasLimit = q.tm.transferGasLimit(job.Data)
+		}
+		hash, err := q.tm.SendTxContext(ctx, job.Signer, job.To, job.Value, job.Data, job.GasPrice, 0, gasLimit)
+		// node may have got the tx when it didn't refuse it, e.g. timeout, its nonce is taken and it may be mined
+		sent := hash != "" && (err == nil || !isRejected(err))
+
+		q.mu.Lock()
+		lane.busy = false
+		if !sent {
+			lane.inFlight--
+		}
+		q.schedule(lane)
+		q.mu.Unlock()
+
+		if !sent {
+			q.finish(f, "", nil, err)
+			continue
+		}
+		if err != nil {
+			logrus.Warnf("send job %v error: %v, waiting receipt of %v", f.id, err, hash)
+		}
+		f.mu.Lock()
+		f.hash = hash
+		f.mu.Unlock()
+		go q.wait(f, hash)
+	}
+}
+
+// wait waits receipt of the sent job, and makes room of in flight for its sender
+func (q *TxQueue) wait(f *TxFuture, hash string) {
+	receipt, err := q.tm.waitSuccess(context.Background(), hash, f.job.ABI)
+
+	q.mu.Lock()
+	f.lane.inFlight--
+	q.schedule(f.lane)
+	q.mu.Unlock()
+
+	q.finish(f, hash, receipt, err)
+}
+
+// finish resolves future of job and reports its event
+func (q *TxQueue) finish(f *TxFuture, hash string, receipt *types.Receipt, err error) {
+	if err != nil {
+		err = &TxJobError{ID: f.id, Label: f.job.Label, Hash: hash, Err: err}
+	}
+	f.mu.Lock()
+	f.hash = hash
+	f.receipt = receipt
+	f.err = err
+	f.mu.Unlock()
+	close(f.done)
+	<-f.lane.slots
+	q.mu.Lock()
+	q.prune(f.lane)
+	q.mu.Unlock()
+
+	q.feed.Send(TxJobEvent{ID: f.id, Label: f.job.Label, From: f.lane.from, Hash: hash, Receipt: receipt, Err: err})
+	q.jobs.Done()
+}
+
+// Close stops accepting jobs, waits submitted jobs finished, and stops workers
+func (q *TxQueue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.jobs.Wait()
+
+	q.mu.Lock()
+	q.stopped = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+	q.workers.Wait()
+}
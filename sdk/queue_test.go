@@ -0,0 +1,283 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newQueueTestTM makes a TransactionManager with nonce manager over backend,
+// which refuses value of 666 as it can't be paid, and reverts value of 1013
+func newQueueTestTM(t *testing.T, backend *testBackend) (*TransactionManager, func()) {
+	backend.set(func(b *testBackend) {
+		b.sendErr = func(tx *types.Transaction) error {
+			if tx.Value().Int64() == 666 {
+				return errors.New("insufficient funds for gas * price + value")
+			}
+			return nil
+		}
+		b.status = func(tx *types.Transaction) uint64 {
+			if tx.Value().Int64() == 1013 {
+				return types.ReceiptStatusFailed
+			}
+			return types.ReceiptStatusSuccessful
+		}
+	})
+	tm, closeFn := backend.newTM(t)
+	tm.timeout = 10
+	tm.EnableNonceManager()
+	return tm, closeFn
+}
+
+func TestTxQueue(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.close()
+	tm, closeFn := newQueueTestTM(t, backend)
+	defer closeFn()
+
+	// blocks are mined until the test ends
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(10 * time.Millisecond):
+				backend.mine()
+			}
+		}
+	}()
+
+	var signers []Signer
+	for i := 0; i < 3; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers = append(signers, NewKeySigner(key))
+	}
+	q, err := tm.NewTxQueue(TxQueueConfig{Workers: 2, MaxInFlight: 3, MaxPending: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan TxJobEvent, 100)
+	sub := q.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	const jobs = 20
+	var futures []*TxFuture
+	for i := 0; i < jobs; i++ {
+		for j, signer := range signers {
+			value := big.NewInt(int64(i + 1))
+			if j == 1 && i == 5 {
+				value = big.NewInt(666)
+			}
+			if j == 2 && i == 7 {
+				value = big.NewInt(1013)
+			}
+			job := TransferEthJob(signer, testAddress(1), value)
+			job.Label = "payout"
+			f, err := q.Submit(context.Background(), job)
+			if err != nil {
+				t.Fatal(err)
+			}
+			futures = append(futures, f)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for i, f := range futures {
+		receipt, err := f.Wait(ctx)
+		j := i % len(signers)
+		var jobErr *TxJobError
+		switch {
+		case j == 1 && i/len(signers) == 5:
+			if !errors.Is(err, ErrInsufficientFunds) || !errors.As(err, &jobErr) || jobErr.ID != f.ID() || jobErr.Hash != "" {
+				t.Fatalf("job %v: expect ErrInsufficientFunds, got %v", i, err)
+			}
+		case j == 2 && i/len(signers) == 7:
+			if !errors.Is(err, ErrReverted) || !errors.As(err, &jobErr) || jobErr.Hash != f.Hash() || receipt == nil {
+				t.Fatalf("job %v: expect revert, got %v", i, err)
+			}
+		default:
+			if err != nil || receipt == nil || receipt.TxHash.String() != f.Hash() {
+				t.Fatalf("job %v: %v %v", i, receipt, err)
+			}
+		}
+	}
+	q.Close()
+
+	// nonces of a sender are sent in order without gap, and in flight is limited
+	for j, signer := range signers {
+		nonces := backend.sentNonces(signer.Address())
+		want := jobs
+		if j == 1 {
+			want--
+		}
+		if len(nonces) != want {
+			t.Fatalf("sender %v: %v transactions sent", j, len(nonces))
+		}
+		for n, nonce := range nonces {
+			if nonce != uint64(n) {
+				t.Fatalf("sender %v: nonces %v", j, nonces)
+			}
+		}
+		backend.set(func(b *testBackend) {
+			if max := b.maxPooled[signer.Address()]; max > 3 {
+				t.Fatalf("sender %v: %v transactions in flight", j, max)
+			}
+		})
+	}
+	// lanes of idle senders are dropped
+	q.mu.Lock()
+	lanes := len(q.lanes)
+	q.mu.Unlock()
+	if lanes != 0 {
+		t.Fatalf("%v lanes are kept", lanes)
+	}
+
+	ids := make(map[uint64]bool)
+	for len(ids) < len(futures) {
+		select {
+		case e := <-events:
+			if e.Label != "payout" || ids[e.ID] {
+				t.Fatalf("event %+v", e)
+			}
+			ids[e.ID] = true
+		case <-ctx.Done():
+			t.Fatalf("%v events", len(ids))
+		}
+	}
+
+	if _, err := q.Submit(context.Background(), TransferEthJob(signers[0], testAddress(1), big.NewInt(1))); err != ErrQueueClosed {
+		t.Fatalf("expect ErrQueueClosed, got %v", err)
+	}
+}
+
+func TestTxQueueBackpressure(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.close()
+	tm, closeFn := newQueueTestTM(t, backend)
+	defer closeFn()
+
+	// nonce manager is required
+	plain, closePlain := backend.newTM(t)
+	defer closePlain()
+	if _, err := plain.NewTxQueue(TxQueueConfig{}); err == nil {
+		t.Fatal("expect error of missing nonce manager")
+	}
+
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := tm.NewTxQueue(TxQueueConfig{Workers: 1, MaxInFlight: 2, MaxPending: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is mined, 2 are in flight and 2 are waiting
+	var futures []*TxFuture
+	for i := 0; i < 4; i++ {
+		f, err := q.TrySubmit(TransferEthJob(signer, testAddress(1), big.NewInt(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, f)
+	}
+	if _, err := q.TrySubmit(TransferEthJob(signer, testAddress(1), big.NewInt(1))); err != ErrQueueFull {
+		t.Fatalf("expect ErrQueueFull, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := q.Submit(ctx, TransferEthJob(signer, testAddress(1), big.NewInt(1))); err != context.DeadlineExceeded {
+		t.Fatalf("expect blocked submit, got %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if sent := len(backend.sentNonces(signer.Address())); sent != 2 {
+		t.Fatalf("%v transactions are sent", sent)
+	}
+
+	// mining makes room
+	backend.mine()
+	if _, err := futures[0].Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		backend.mine()
+		time.Sleep(20 * time.Millisecond)
+	}
+	for _, f := range futures {
+		if _, err := f.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.Close()
+}
+
+func TestTxQueueLostResponse(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.close()
+	tm, closeFn := newQueueTestTM(t, backend)
+	defer closeFn()
+	// the first tx reaches node, but its response is lost
+	backend.set(func(b *testBackend) {
+		b.lost = func(tx *types.Transaction) bool {
+			return tx.Nonce() == 0
+		}
+	})
+
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := tm.NewTxQueue(TxQueueConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	var futures []*TxFuture
+	for i := 0; i < 2; i++ {
+		f, err := q.Submit(context.Background(), TransferEthJob(signer, testAddress(1), big.NewInt(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, f)
+	}
+
+	// the job waits receipt of its tx instead of failing, and its nonce is not reused
+	deadline := time.Now().Add(time.Second)
+	for len(backend.sentNonces(signer.Address())) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-futures[0].Done():
+		_, err := futures[0].Wait(context.Background())
+		t.Fatalf("job of lost response is finished: %v", err)
+	default:
+	}
+	if nonces := backend.sentNonces(signer.Address()); len(nonces) != 2 || nonces[0] != 0 || nonces[1] != 1 {
+		t.Fatalf("nonces %v", nonces)
+	}
+
+	backend.mine()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i, f := range futures {
+		receipt, err := f.Wait(ctx)
+		if err != nil || receipt == nil || receipt.TxHash.String() != f.Hash() {
+			t.Fatalf("job %v: %v %v", i, receipt, err)
+		}
+	}
+	// gas limit of transfer is decided as TransferEth does
+	if gas := backend.lastSent(t).Gas(); gas != transferEthLimit {
+		t.Fatalf("gas limit %v", gas)
+	}
+}
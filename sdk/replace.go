@@ -0,0 +1,219 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// node accepts a replacement transaction only when its fee is at least 10% higher
+	replaceBumpPercent = 10
+)
+
+// replacements records transactions competing for the same nonce,
+// a group is removed once one of its transactions is mined
+type replacements struct {
+	mu     sync.Mutex
+	groups map[common.Hash]*[]common.Hash // every hash of a group points to the group
+}
+
+func (r *replacements) add(original, replacement common.Hash) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.groups == nil {
+		r.groups = make(map[common.Hash]*[]common.Hash)
+	}
+	group, ok := r.groups[original]
+	if !ok {
+		group = &[]common.Hash{original}
+		r.groups[original] = group
+	}
+	*group = append(*group, replacement)
+	r.groups[replacement] = group
+}
+
+func (r *replacements) get(hash common.Hash) []common.Hash {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	group, ok := r.groups[hash]
+	if !ok {
+		return []common.Hash{hash}
+	}
+	return append([]common.Hash{}, *group...)
+}
+
+// remove forgets the group of hash, after one of the group is mined
+func (r *replacements) remove(hash common.Hash) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	group, ok := r.groups[hash]
+	if !ok {
+		return
+	}
+	for _, h := range *group {
+		delete(r.groups, h)
+	}
+}
+
+// bumpFee returns fee raised by replaceBumpPercent, rounded up
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+replaceBumpPercent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// SpeedUp re-sends pending tx of hash with the same nonce and a fee bumped by at least 10%,
+// return hash of the replacement tx
+func (tm *TransactionManager) SpeedUp(signer Signer, hash string) (string, error) {
+	return tm.SpeedUpContext(context.Background(), signer, hash)
+}
+
+// SpeedUpContext is like SpeedUp but with ctx for cancellation and deadline
+func (tm *TransactionManager) SpeedUpContext(ctx context.Context, signer Signer, hash string) (string, error) {
+	return tm.replace(ctx, signer, hash, false)
+}
+
+// Cancel replaces pending tx of hash with a zero-value self-transfer of the same nonce and a fee bumped by at least 10%,
+// return hash of the replacement tx
+func (tm *TransactionManager) Cancel(signer Signer, hash string) (string, error) {
+	return tm.CancelContext(context.Background(), signer, hash)
+}
+
+// CancelContext is like Cancel but with ctx for cancellation and deadline
+func (tm *TransactionManager) CancelContext(ctx context.Context, signer Signer, hash string) (string, error) {
+	return tm.replace(ctx, signer, hash, true)
+}
+
+func (tm *TransactionManager) replace(ctx context.Context, signer Signer, hash string, cancel bool) (string, error) {
+	fromAddress := signer.Address()
+	tx, pending, err := tm.Client.TransactionByHash(ctx, common.HexToHash(hash))
+	if err != nil {
+		return "", fmt.Errorf("TransactionByHash() error: %s", err.Error())
+	}
+	if !pending {
+		return "", fmt.Errorf("transaction %v is not pending", hash)
+	}
+	txSigner, err := tm.txSigner()
+	if err != nil {
+		return "", err
+	}
+	sender, err := types.Sender(txSigner, tx)
+	if err != nil {
+		return "", fmt.Errorf("get sender of transaction %v error: %s", hash, err.Error())
+	}
+	if sender != fromAddress {
+		return "", fmt.Errorf("transaction %v is not sent by %v", hash, fromAddress.String())
+	}
+
+	to, value, data, gas, accessList := tx.To(), tx.Value(), tx.Data(), tx.Gas(), tx.AccessList()
+	if cancel {
+		to, value, data, gas, accessList = &fromAddress, big.NewInt(0), nil, transferEthLimit, nil
+	}
+
+	var txData types.TxData
+	switch tx.Type() {
+	case types.DynamicFeeTxType:
+		tip, feeCap, err := tm.dynamicFees(ctx, nil)
+		if err != nil {
+			return "", err
+		}
+		tip = maxBig(bumpFee(tx.GasTipCap()), tip)
+		feeCap = maxBig(bumpFee(tx.GasFeeCap()), feeCap)
+		txData = &types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tip,
+			GasFeeCap:  feeCap,
+			Gas:        gas,
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
+		}
+	default:
+		price, err := tm.gasPriceStrategy.GasPrice(ctx)
+		if err != nil {
+			return "", err
+		}
+		price = maxBig(bumpFee(tx.GasPrice()), price)
+		if tx.Type() == types.AccessListTxType {
+			txData = &types.AccessListTx{
+				ChainID:    tx.ChainId(),
+				Nonce:      tx.Nonce(),
+				GasPrice:   price,
+				Gas:        gas,
+				To:         to,
+				Value:      value,
+				Data:       data,
+				AccessList: accessList,
+			}
+		} else {
+			txData = &types.LegacyTx{
+				Nonce:    tx.Nonce(),
+				GasPrice: price,
+				Gas:      gas,
+				To:       to,
+				Value:    value,
+				Data:     data,
+			}
+		}
+	}
+
+	signedTx, err := signer.SignTx(ctx, txSigner, types.NewTx(txData))
+	if err != nil {
+		return "", fmt.Errorf("sign tx error: %s", err.Error())
+	}
+	if err := tm.broadcast(ctx, signer.Address(), signedTx); err != nil {
+		return "", err
+	}
+	tm.replacements.add(tx.Hash(), signedTx.Hash())
+	return signedTx.Hash().String(), nil
+}
+
+// Replacements returns hash and the hashes of all transactions competing with it for the same nonce,
+// which are sent by SpeedUp or Cancel, until one of them is seen mined by MinedReplacement or a wait of receipt
+func (tm *TransactionManager) Replacements(hash string) []string {
+	var ret []string
+	for _, h := range tm.replacements.get(common.HexToHash(hash)) {
+		ret = append(ret, h.String())
+	}
+	return ret
+}
+
+// MinedReplacement returns hash and receipt of the mined one among hash and its replacements
+// return ethereum.NotFound when none of them is mined, replacements of hash are forgotten once one is mined
+func (tm *TransactionManager) MinedReplacement(hash string) (string, *types.Receipt, error) {
+	return tm.MinedReplacementContext(context.Background(), hash)
+}
+
+// MinedReplacementContext is like MinedReplacement but with ctx for cancellation and deadline
+func (tm *TransactionManager) MinedReplacementContext(ctx context.Context, hash string) (string, *types.Receipt, error) {
+	for _, h := range tm.replacements.get(common.HexToHash(hash)) {
+		receipt, err := tm.Client.TransactionReceipt(ctx, h)
+		if err == ethereum.NotFound {
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		tm.replacements.remove(h)
+		return h.String(), receipt, nil
+	}
+	return "", nil, ethereum.NotFound
+}
@@ -0,0 +1,51 @@
+package sdk
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBumpFee(t *testing.T) {
+	cases := []struct {
+		fee  int64
+		want int64
+	}{
+		{100, 110},
+		{1, 2},
+		{1e9, 1.1e9},
+		{999, 1099},
+	}
+	for _, c := range cases {
+		if got := bumpFee(big.NewInt(c.fee)); got.Int64() != c.want {
+			t.Errorf("bumpFee(%v): got %v want %v", c.fee, got, c.want)
+		}
+	}
+}
+
+func TestReplacements(t *testing.T) {
+	var r replacements
+	h1, h2, h3 := common.HexToHash("0x01"), common.HexToHash("0x02"), common.HexToHash("0x03")
+	r.add(h1, h2)
+	r.add(h2, h3)
+
+	for _, h := range []common.Hash{h1, h2, h3} {
+		group := r.get(h)
+		if len(group) != 3 || group[0] != h1 || group[1] != h2 || group[2] != h3 {
+			t.Fatalf("group of %v: %v", h.String(), group)
+		}
+	}
+	if group := r.get(common.HexToHash("0x04")); len(group) != 1 {
+		t.Fatalf("group of unknown hash: %v", group)
+	}
+
+	// one of the group is mined
+	r.remove(h2)
+	if len(r.groups) != 0 {
+		t.Fatalf("groups left: %v", r.groups)
+	}
+	if group := r.get(h1); len(group) != 1 || group[0] != h1 {
+		t.Fatalf("group of removed hash: %v", group)
+	}
+}
@@ -0,0 +1,200 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// RetryPolicy retries calls failed with transient errors, with exponential backoff and jitter
+type RetryPolicy struct {
+	// MaxAttempts is the attempt budget of a call including the first one, 0 or 1 disables retry
+	MaxAttempts int
+	// BaseDelay is the delay before the second attempt, it's doubled for every attempt after it
+	BaseDelay time.Duration
+	// MaxDelay caps the delay, 0 for no cap
+	MaxDelay time.Duration
+	// Jitter in [0, 1] is the random part of a delay, e.g. 0.5 makes delay d random in [d/2, d]
+	Jitter float64
+	// Retryable classifies errors, nil for IsRetryable
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy returns a new copy of the policy of TransactionManager and package level helpers such as GetBalance,
+// changing it doesn't affect others
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    8 * time.Second,
+		Jitter:      0.5,
+	}
+}
+
+// packageRetryPolicy makes policy of package level helpers, it's replaced by tests
+var packageRetryPolicy = DefaultRetryPolicy
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Do calls fn until it succeeds, fails with a terminal error, the attempt budget is used up or ctx is done,
+// it returns the last error of fn. p can be nil, which calls fn once
+func (p *RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	attempts := 1
+	if p != nil && p.MaxAttempts > 1 {
+		attempts = p.MaxAttempts
+	}
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= attempts || !p.retryable(err) {
+			return err
+		}
+		delay := p.backoff(attempt)
+		logrus.Debugf("attempt %v failed: %v, retry in %v", attempt, err, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// backoff returns delay after the attempt-th attempt
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		jitterMu.Lock()
+		r := jitterRand.Float64()
+		jitterMu.Unlock()
+		delay -= time.Duration(float64(delay) * jitter * r)
+	}
+	return delay
+}
+
+// transientMessages are parts of error messages of transient failures,
+// e.g. "header not found" of a node that has not imported the requested block yet
+var transientMessages = []string{
+	"header not found",
+	"unknown block",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"i/o timeout",
+	"too many requests",
+	"rate limit",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+	"http status 5",
+}
+
+// IsRetryable reports whether err is transient, such as http 5xx and 429, connection errors, timeouts and "header not found",
+// errors of cancelled context, reverts, transactions rejected by node, TLS and malformed URL errors are terminal
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || err == ethereum.NotFound {
+		return false
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == 429 || httpErr.StatusCode == 408
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32005 {
+		// limit exceeded
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	// *url.Error of http client is a net.Error of the error it wraps
+	var netErr net.Error
+	if errors.As(err, &netErr) && (netErr.Timeout() || netErr.Temporary()) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRetryPolicy sets retry policy of reads, sends and receipt polling, nil disables retry
+func (tm *TransactionManager) SetRetryPolicy(p *RetryPolicy) {
+	tm.retry = p
+	tm.waiter.retry = p
+}
+
+// txSender is the part of *ethclient.Client used by sendSignedTx
+type txSender interface {
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+}
+
+// sendSignedTx sends tx with retry policy p. a failed send is retried with the same signed bytes only,
+// so it can't be sent twice with different nonces or fees: a retry is "already known"
+// or "nonce too low" when an earlier attempt has reached the node, which is the success of it.
+// node errors such as "nonce too low" are returned matching sentinels of them
+func sendSignedTx(ctx context.Context, p *RetryPolicy, client txSender, tx *types.Transaction) error {
+	attempt := 0
+	err := p.Do(ctx, func() error {
+		attempt++
+		err := client.SendTransaction(ctx, tx)
+		if err == nil || attempt == 1 {
+			return err
+		}
+		if isAlreadyKnown(err) {
+			return nil
+		}
+		if isNonceTooLow(err) {
+			// mined already
+			if _, _, e := client.TransactionByHash(ctx, tx.Hash()); e == nil {
+				return nil
+			}
+		}
+		return err
+	})
+	return classifyNodeError(err)
+}
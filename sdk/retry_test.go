@@ -0,0 +1,241 @@
+package sdk
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var fastRetry = &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond, Jitter: 0.5}
+
+func TestRetryPolicyDo(t *testing.T) {
+	transient := errors.New("header not found")
+	terminal := errors.New("execution reverted")
+
+	calls := 0
+	err := fastRetry.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return transient
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("transient: %v after %v calls", err, calls)
+	}
+
+	calls = 0
+	err = fastRetry.Do(context.Background(), func() error {
+		calls++
+		return transient
+	})
+	if err != transient || calls != 3 {
+		t.Fatalf("budget: %v after %v calls", err, calls)
+	}
+
+	calls = 0
+	err = fastRetry.Do(context.Background(), func() error {
+		calls++
+		return terminal
+	})
+	if err != terminal || calls != 1 {
+		t.Fatalf("terminal: %v after %v calls", err, calls)
+	}
+
+	// nil policy calls once
+	calls = 0
+	var p *RetryPolicy
+	if err := p.Do(context.Background(), func() error { calls++; return transient }); err != transient || calls != 1 {
+		t.Fatalf("nil policy: %v after %v calls", err, calls)
+	}
+
+	// no more attempts after ctx is done
+	slow := &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	calls = 0
+	if err := slow.Do(ctx, func() error { calls++; return transient }); err != transient || calls != 1 {
+		t.Fatalf("cancelled: %v after %v calls", err, calls)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w*time.Millisecond {
+			t.Errorf("attempt %v: got %v want %v", i+1, got, w*time.Millisecond)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.backoff(2)
+		if d < 100*time.Millisecond || d > 200*time.Millisecond {
+			t.Fatalf("jittered delay %v out of range", d)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, false},
+		{ethereum.NotFound, false},
+		{rpc.HTTPError{StatusCode: 502, Status: "502 Bad Gateway"}, true},
+		{rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, true},
+		{rpc.HTTPError{StatusCode: 401, Status: "401 Unauthorized"}, false},
+		{fmt.Errorf("post: %w", syscall.ECONNRESET), true},
+		{io.ErrUnexpectedEOF, true},
+		{errors.New("header not found"), true},
+		{errors.New("execution reverted: not owner"), false},
+		{errors.New("nonce too low"), false},
+		{errors.New("insufficient funds for gas * price + value"), false},
+		{&url.Error{Op: "Post", URL: "http://node", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, true},
+		{&url.Error{Op: "Post", URL: "http://node", Err: testNetError{timeout: true}}, true},
+		{&url.Error{Op: "Post", URL: "http://node", Err: testNetError{temporary: true}}, true},
+		{&url.Error{Op: "Post", URL: "https://node", Err: x509.UnknownAuthorityError{}}, false},
+		{&url.Error{Op: "parse", URL: "node:8545", Err: errors.New("first path segment in URL cannot contain colon")}, false},
+	}
+	for _, c := range cases {
+		if got := IsRetryable(c.err); got != c.want {
+			t.Errorf("%v: got %v", c.err, got)
+		}
+	}
+}
+
+// testNetError is a net.Error
+type testNetError struct {
+	timeout   bool
+	temporary bool
+}
+
+func (e testNetError) Error() string   { return "net error" }
+func (e testNetError) Timeout() bool   { return e.timeout }
+func (e testNetError) Temporary() bool { return e.temporary }
+
+// flakySender fails sends with errs in turn, and records sent transactions
+type flakySender struct {
+	errs  []error
+	sent  []*types.Transaction
+	known bool // TransactionByHash finds the tx
+}
+
+func (s *flakySender) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	s.sent = append(s.sent, tx)
+	if len(s.errs) == 0 {
+		return nil
+	}
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func (s *flakySender) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if s.known {
+		return s.sent[0], false, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func TestSendSignedTx(t *testing.T) {
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(5)
+	tx, err := signer.SignTx(context.Background(), types.NewLondonSigner(chainID), testTxs(chainID)[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		errs  []error
+		known bool
+		sends int
+		fail  bool
+	}{
+		{"ok", nil, false, 1, false},
+		{"transient", []error{errors.New("502 Bad Gateway")}, false, 2, false},
+		{"reached node before", []error{errors.New("connection reset by peer"), errors.New("already known")}, false, 2, false},
+		{"mined before", []error{errors.New("connection reset by peer"), errors.New("nonce too low")}, true, 2, false},
+		{"nonce too low", []error{errors.New("connection reset by peer"), errors.New("nonce too low")}, false, 2, true},
+		{"already known at first", []error{errors.New("already known")}, false, 1, true},
+		{"terminal", []error{errors.New("insufficient funds for gas * price + value")}, false, 1, true},
+		{"budget", []error{errors.New("i/o timeout"), errors.New("i/o timeout"), errors.New("i/o timeout")}, false, 3, true},
+	}
+	for _, c := range cases {
+		sender := &flakySender{errs: c.errs, known: c.known}
+		err := sendSignedTx(context.Background(), fastRetry, sender, tx)
+		if (err != nil) != c.fail {
+			t.Errorf("%v: error %v", c.name, err)
+		}
+		if len(sender.sent) != c.sends {
+			t.Errorf("%v: %v sends, want %v", c.name, len(sender.sent), c.sends)
+		}
+		for _, sent := range sender.sent {
+			if sent != tx {
+				t.Errorf("%v: tx is re-signed", c.name)
+			}
+		}
+	}
+}
+
+func TestGetBalanceRetry(t *testing.T) {
+	defer func(p func() *RetryPolicy) { packageRetryPolicy = p }(packageRetryPolicy)
+	packageRetryPolicy = func() *RetryPolicy { return fastRetry }
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		switch n {
+		case 1:
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		case 2:
+			json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32000, "message": "header not found"}})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "0x64"})
+		}
+	}))
+	defer server.Close()
+
+	balance, err := GetBalance(server.URL, "0x00000000000000000000000000000000000000aa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Int64() != 100 || calls != 3 {
+		t.Fatalf("balance %v after %v calls", balance, calls)
+	}
+}
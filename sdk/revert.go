@@ -0,0 +1,240 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// selector of Error(string)
+	errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	// selector of Panic(uint256)
+	panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+
+	// panic codes of solidity
+	panicReasons = map[uint64]string{
+		0x00: "generic panic",
+		0x01: "assert(false)",
+		0x11: "arithmetic underflow or overflow",
+		0x12: "division or modulo by zero",
+		0x21: "enum overflow",
+		0x22: "invalid encoded storage byte array accessed",
+		0x31: "out-of-bounds array access; popping on an empty array",
+		0x32: "out-of-bounds access of an array or bytesN",
+		0x41: "out of memory",
+		0x51: "uninitialized function",
+	}
+)
+
+// RevertError is returned when a transaction or call is reverted
+type RevertError struct {
+	Hash   string // hash of the reverted transaction, empty for call
+	Reason string // decoded revert reason, empty when it's unknown
+	Data   []byte // raw revert data
+}
+
+func (e *RevertError) Error() string {
+	msg := "execution reverted"
+	if e.Hash != "" {
+		msg = fmt.Sprintf("transaction %v reverted", e.Hash)
+	}
+	if e.Reason != "" {
+		return msg + ": " + e.Reason
+	}
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("%v: %x", msg, e.Data)
+	}
+	return msg
+}
+
+// abiError is a custom error of solidity declared in abi
+type abiError struct {
+	Name   string
+	Inputs abi.Arguments
+}
+
+func (e *abiError) selector() []byte {
+	types := make([]string, len(e.Inputs))
+	for i, input := range e.Inputs {
+		types[i] = input.Type.String()
+	}
+	return crypto.Keccak256([]byte(fmt.Sprintf("%v(%v)", e.Name, strings.Join(types, ","))))[:4]
+}
+
+// parseABI parses abiStr, custom errors are returned separately because abi.JSON can't recognize them
+func parseABI(abiStr string) (abi.ABI, []abiError, error) {
+	var fields []json.RawMessage
+	if err := json.Unmarshal([]byte(abiStr), &fields); err != nil {
+		return abi.ABI{}, nil, err
+	}
+	var (
+		others []json.RawMessage
+		errs   []abiError
+	)
+	for _, field := range fields {
+		var entry struct {
+			Type   string
+			Name   string
+			Inputs []abi.Argument
+		}
+		if err := json.Unmarshal(field, &entry); err != nil {
+			return abi.ABI{}, nil, err
+		}
+		if entry.Type == "error" {
+			errs = append(errs, abiError{Name: entry.Name, Inputs: entry.Inputs})
+			continue
+		}
+		others = append(others, field)
+	}
+	filtered, err := json.Marshal(others)
+	if err != nil {
+		return abi.ABI{}, nil, err
+	}
+	abiObj, err := abi.JSON(bytes.NewReader(filtered))
+	if err != nil {
+		return abi.ABI{}, nil, err
+	}
+	return abiObj, errs, nil
+}
+
+// DecodeRevertReason decodes revert data as Error(string), Panic(uint256) or a custom error declared in abiStr,
+// set abiStr to empty string when there is no abi, return empty string when data can't be decoded
+func DecodeRevertReason(data []byte, abiStr string) string {
+	if len(data) < 4 {
+		return ""
+	}
+	selector, args := data[:4], data[4:]
+
+	switch {
+	case bytes.Equal(selector, errorSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return ""
+		}
+		return reason
+
+	case bytes.Equal(selector, panicSelector):
+		typ, _ := abi.NewType("uint256", "", nil)
+		unpacked, err := (abi.Arguments{{Type: typ}}).Unpack(args)
+		if err != nil || len(unpacked) != 1 {
+			return ""
+		}
+		code := unpacked[0].(*big.Int)
+		if reason, ok := panicReasons[code.Uint64()]; ok && code.IsUint64() {
+			return fmt.Sprintf("panic 0x%x: %v", code, reason)
+		}
+		return fmt.Sprintf("panic 0x%x", code)
+	}
+
+	if abiStr == "" {
+		return ""
+	}
+	_, errs, err := parseABI(abiStr)
+	if err != nil {
+		return ""
+	}
+	for _, e := range errs {
+		if !bytes.Equal(selector, e.selector()) {
+			continue
+		}
+		unpacked, err := e.Inputs.Unpack(args)
+		if err != nil {
+			return ""
+		}
+		values := make([]string, len(unpacked))
+		for i, v := range unpacked {
+			values[i] = fmt.Sprintf("%v", v)
+		}
+		return fmt.Sprintf("%v(%v)", e.Name, strings.Join(values, ", "))
+	}
+	return ""
+}
+
+// revertData returns revert data carried by rpc error of eth_call or eth_estimateGas
+func revertData(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	s, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil, false
+	}
+	data, decodeErr := hexutil.Decode(s)
+	if decodeErr != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// newRevertError makes RevertError from error of eth_call or eth_estimateGas, nil when err is not a revert
+func newRevertError(err error, abiStr string) *RevertError {
+	data, ok := revertData(err)
+	if !ok {
+		if err != nil && strings.Contains(err.Error(), "execution reverted") {
+			return &RevertError{}
+		}
+		return nil
+	}
+	return &RevertError{
+		Reason: DecodeRevertReason(data, abiStr),
+		Data:   data,
+	}
+}
+
+// checkReceipt returns RevertError when tx of receipt failed,
+// the reason is got by replaying the tx as a call at the block it's mined in
+// custom errors declared in abiStr are decoded, set it to empty string when there is no abi
+func (tm *TransactionManager) checkReceipt(ctx context.Context, receipt *types.Receipt, abiStr string) error {
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return nil
+	}
+	revertErr := &RevertError{Hash: receipt.TxHash.String()}
+
+	tx, _, err := tm.Client.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		return revertErr
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return revertErr
+	}
+	msg := ethereum.CallMsg{
+		From:       from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+	_, err = tm.Client.CallContract(ctx, msg, receipt.BlockNumber)
+	if callErr := newRevertError(err, abiStr); callErr != nil {
+		revertErr.Reason = callErr.Reason
+		revertErr.Data = callErr.Data
+	} else if err != nil {
+		// e.g. out of gas, which has no revert data
+		revertErr.Reason = err.Error()
+	}
+	return revertErr
+}
+
+// waitSuccess waits receipt of hash like waitReceipt, and returns RevertError with the receipt when tx failed
+func (tm *TransactionManager) waitSuccess(ctx context.Context, hash string, abiStr string) (*types.Receipt, error) {
+	receipt, err := tm.waitReceipt(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return receipt, tm.checkReceipt(ctx, receipt, abiStr)
+}
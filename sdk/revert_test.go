@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const customErrorABI = `[{"inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}],"name":"InsufficientBalance","type":"error"},{"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transfer","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+
+func encodeRevert(t *testing.T, selector []byte, typ string, value interface{}) []byte {
+	abiType, err := abi.NewType(typ, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed, err := (abi.Arguments{{Type: abiType}}).Pack(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(append([]byte{}, selector...), packed...)
+}
+
+type testDataError struct {
+	data interface{}
+}
+
+func (e *testDataError) Error() string          { return "execution reverted" }
+func (e *testDataError) ErrorData() interface{} { return e.data }
+
+func TestDecodeRevertReason(t *testing.T) {
+	uint256, _ := abi.NewType("uint256", "", nil)
+	custom, err := (abi.Arguments{{Type: uint256}, {Type: uint256}}).Pack(big.NewInt(1), big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	customSelector := (&abiError{Name: "InsufficientBalance", Inputs: abi.Arguments{{Type: uint256}, {Type: uint256}}}).selector()
+
+	cases := []struct {
+		data []byte
+		abi  string
+		want string
+	}{
+		{encodeRevert(t, errorSelector, "string", "not owner"), "", "not owner"},
+		{encodeRevert(t, panicSelector, "uint256", big.NewInt(0x11)), "", "panic 0x11: arithmetic underflow or overflow"},
+		{encodeRevert(t, panicSelector, "uint256", big.NewInt(0x99)), "", "panic 0x99"},
+		{append(customSelector, custom...), customErrorABI, "InsufficientBalance(1, 100)"},
+		{append(customSelector, custom...), "", ""},
+		{[]byte{1, 2}, "", ""},
+	}
+	for i, c := range cases {
+		if got := DecodeRevertReason(c.data, c.abi); got != c.want {
+			t.Errorf("case %v: got '%v' want '%v'", i, got, c.want)
+		}
+	}
+}
+
+func TestNewRevertError(t *testing.T) {
+	data := encodeRevert(t, errorSelector, "string", "paused")
+	err := fmt.Errorf("call error: %w", &testDataError{data: hexutil.Encode(data)})
+
+	revertErr := newRevertError(err, "")
+	if revertErr == nil || revertErr.Reason != "paused" {
+		t.Fatalf("unexpected revert error: %v", revertErr)
+	}
+	if newRevertError(errors.New("connection refused"), "") != nil {
+		t.Fatal("non revert error is decoded as revert")
+	}
+}
+
+func TestPackWithCustomErrors(t *testing.T) {
+	if _, err := Pack(customErrorABI, "transfer", "address:0xd69cfc58b5a8b3b7866d2c2682ba971074a946a0;uint256:3;"); err != nil {
+		t.Fatalf("pack with custom errors in abi: %v", err)
+	}
+}
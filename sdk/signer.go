@@ -0,0 +1,184 @@
+package sdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Signer signs transactions of an account without exposing its private key
+type Signer interface {
+	// Address returns address of the account
+	Address() common.Address
+	// SignTx signs tx with txSigner, which decides chain id and signature scheme
+	SignTx(ctx context.Context, txSigner types.Signer, tx *types.Transaction) (*types.Transaction, error)
+}
+
+// KeySigner signs with a private key in memory
+type KeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewKeySigner makes KeySigner of key
+func NewKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+	}
+}
+
+// HexToSigner makes KeySigner of hex string of private key
+func HexToSigner(hexPrivKey string) (*KeySigner, error) {
+	privK, _, _, err := HexToAccount(hexPrivKey)
+	if err != nil {
+		return nil, fmt.Errorf("convert hex sk to ECDSA error: %s", err.Error())
+	}
+	return NewKeySigner(privK), nil
+}
+
+// NewKeystoreSigner makes KeySigner of key in keystore file utcFile encrypted with passphrase
+func NewKeystoreSigner(utcFile string, passphrase string) (*KeySigner, error) {
+	keyJSON, err := ioutil.ReadFile(utcFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(key.PrivateKey), nil
+}
+
+func (s *KeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *KeySigner) SignTx(ctx context.Context, txSigner types.Signer, tx *types.Transaction) (*types.Transaction, error) {
+	return types.SignTx(tx, txSigner, s.key)
+}
+
+// String shows address only, so that the key never ends up in logs
+func (s *KeySigner) String() string {
+	return fmt.Sprintf("KeySigner(%v)", s.address.String())
+}
+
+// GoString is like String for %#v
+func (s *KeySigner) GoString() string {
+	return s.String()
+}
+
+// signTxArgs is the argument of account_signTransaction, compatible with clef
+type signTxArgs struct {
+	From                 common.MixedcaseAddress  `json:"from"`
+	To                   *common.MixedcaseAddress `json:"to"`
+	Gas                  hexutil.Uint64           `json:"gas"`
+	GasPrice             *hexutil.Big             `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big             `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big             `json:"maxPriorityFeePerGas"`
+	Value                hexutil.Big              `json:"value"`
+	Nonce                hexutil.Uint64           `json:"nonce"`
+	Data                 *hexutil.Bytes           `json:"data"`
+	AccessList           *types.AccessList        `json:"accessList,omitempty"`
+	ChainID              *hexutil.Big             `json:"chainId,omitempty"`
+}
+
+// signTxResult is the result of account_signTransaction
+type signTxResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// ExternalSigner asks an external signer such as clef to sign over JSON-RPC (account_signTransaction)
+type ExternalSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+// NewExternalSigner dials external signer at endpoint, which signs for address
+func NewExternalSigner(ctx context.Context, endpoint string, address string) (*ExternalSigner, error) {
+	client, err := dialRPCContext(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return NewExternalSignerWithClient(client, address), nil
+}
+
+// NewExternalSignerWithClient makes ExternalSigner over an rpc client, which signs for address
+func NewExternalSignerWithClient(client *rpc.Client, address string) *ExternalSigner {
+	return &ExternalSigner{
+		client:  client,
+		address: common.HexToAddress(address),
+	}
+}
+
+// Accounts returns accounts managed by the external signer
+func (s *ExternalSigner) Accounts(ctx context.Context) ([]common.Address, error) {
+	var accounts []common.Address
+	if err := s.client.CallContext(ctx, &accounts, "account_list"); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (s *ExternalSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *ExternalSigner) SignTx(ctx context.Context, txSigner types.Signer, tx *types.Transaction) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	args := signTxArgs{
+		From:  common.NewMixedcaseAddress(s.address),
+		Gas:   hexutil.Uint64(tx.Gas()),
+		Value: hexutil.Big(*tx.Value()),
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		Data:  &data,
+	}
+	if tx.To() != nil {
+		to := common.NewMixedcaseAddress(*tx.To())
+		args.To = &to
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+	if tx.Type() != types.LegacyTxType {
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	}
+	if chainID := txSigner.ChainID(); chainID != nil && chainID.Sign() != 0 {
+		args.ChainID = (*hexutil.Big)(chainID)
+	}
+
+	var result signTxResult
+	// pointer makes MixedcaseAddress marshal as string
+	if err := s.client.CallContext(ctx, &result, "account_signTransaction", &args); err != nil {
+		return nil, fmt.Errorf("account_signTransaction error: %s", err.Error())
+	}
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(result.Raw); err != nil {
+		return nil, fmt.Errorf("decode signed transaction error: %s", err.Error())
+	}
+	// the external signer must sign exactly what is asked, by the expected account
+	if txSigner.Hash(signedTx) != txSigner.Hash(tx) {
+		return nil, fmt.Errorf("external signer signed a different transaction")
+	}
+	sender, err := types.Sender(txSigner, signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("get sender of signed transaction error: %s", err.Error())
+	}
+	if sender != s.address {
+		return nil, fmt.Errorf("transaction is signed by %v instead of %v", sender.String(), s.address.String())
+	}
+	return signedTx, nil
+}
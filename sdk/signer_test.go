@@ -0,0 +1,223 @@
+package sdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const testSignerKey = "e6762f0afd9736216cf42238ce8e7b1b92f17903950dbe6f0ba815a99bedfce0"
+
+func testTxs(chainID *big.Int) []*types.Transaction {
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	return []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1e9), Gas: 21000, To: &to, Value: big.NewInt(1)}),
+		types.NewTx(&types.AccessListTx{ChainID: chainID, Nonce: 2, GasPrice: big.NewInt(1e9), Gas: 30000, To: &to, Data: []byte{1, 2}}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 3, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(3e9), Gas: 21000, To: &to}),
+		types.NewTx(&types.LegacyTx{Nonce: 4, GasPrice: big.NewInt(1e9), Gas: 100000, Data: []byte{0x60, 0x00}}),
+	}
+}
+
+func checkSigner(t *testing.T, signer Signer, want common.Address) {
+	if signer.Address() != want {
+		t.Fatalf("address: got %v want %v", signer.Address().String(), want.String())
+	}
+	chainID := big.NewInt(5)
+	txSigner := types.NewLondonSigner(chainID)
+	for _, tx := range testTxs(chainID) {
+		signedTx, err := signer.SignTx(context.Background(), txSigner, tx)
+		if err != nil {
+			t.Fatalf("sign tx %v error: %v", tx.Nonce(), err)
+		}
+		sender, err := types.Sender(txSigner, signedTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sender != want {
+			t.Fatalf("sender of tx %v: %v", tx.Nonce(), sender.String())
+		}
+	}
+}
+
+func TestKeySigner(t *testing.T) {
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSigner(t, signer, crypto.PubkeyToAddress(signer.key.PublicKey))
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		if out := fmt.Sprintf(format, signer); strings.Contains(out, testSignerKey) || strings.Contains(out, signer.key.D.String()) {
+			t.Fatalf("%v leaks key: %v", format, out)
+		}
+	}
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	privK, err := crypto.HexToECDSA(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.ImportECDSA(privK, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utcFile := account.URL.Path
+
+	if _, err := NewKeystoreSigner(utcFile, "wrong"); err == nil {
+		t.Fatal("expect error of wrong passphrase")
+	}
+	signer, err := NewKeystoreSigner(utcFile, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSigner(t, signer, account.Address)
+}
+
+// standInSigner serves account_* methods like clef with a local key
+type standInSigner struct {
+	key    *ecdsa.PrivateKey
+	tamper bool // sign a different nonce
+}
+
+func (s *standInSigner) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(s.key.PublicKey)}
+}
+
+func (s *standInSigner) SignTransaction(args signTxArgs) (*signTxResult, error) {
+	if args.From.Address() != crypto.PubkeyToAddress(s.key.PublicKey) {
+		return nil, fmt.Errorf("unknown account %v", args.From.Address().String())
+	}
+	nonce := uint64(args.Nonce)
+	if s.tamper {
+		nonce++
+	}
+	var to *common.Address
+	if args.To != nil {
+		address := args.To.Address()
+		to = &address
+	}
+	var data []byte
+	if args.Data != nil {
+		data = *args.Data
+	}
+	var txData types.TxData
+	switch {
+	case args.MaxFeePerGas != nil:
+		txData = &types.DynamicFeeTx{ChainID: args.ChainID.ToInt(), Nonce: nonce, GasTipCap: args.MaxPriorityFeePerGas.ToInt(), GasFeeCap: args.MaxFeePerGas.ToInt(), Gas: uint64(args.Gas), To: to, Value: args.Value.ToInt(), Data: data, AccessList: *args.AccessList}
+	case args.AccessList != nil:
+		txData = &types.AccessListTx{ChainID: args.ChainID.ToInt(), Nonce: nonce, GasPrice: args.GasPrice.ToInt(), Gas: uint64(args.Gas), To: to, Value: args.Value.ToInt(), Data: data, AccessList: *args.AccessList}
+	default:
+		txData = &types.LegacyTx{Nonce: nonce, GasPrice: args.GasPrice.ToInt(), Gas: uint64(args.Gas), To: to, Value: args.Value.ToInt(), Data: data}
+	}
+	signedTx, err := types.SignNewTx(s.key, types.NewLondonSigner(args.ChainID.ToInt()), txData)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signedTx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &signTxResult{Raw: hexutil.Bytes(raw), Tx: signedTx}, nil
+}
+
+// SignData signs text/plain data with EIP-191 prefix as clef does, V is 27 or 28
+func (s *standInSigner) SignData(contentType string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if contentType != accounts.MimetypeTextPlain {
+		return nil, fmt.Errorf("unsupported content type %v", contentType)
+	}
+	if addr.Address() != crypto.PubkeyToAddress(s.key.PublicKey) {
+		return nil, fmt.Errorf("unknown account %v", addr.Address().String())
+	}
+	if s.tamper {
+		data = append(data, '!')
+	}
+	return signHash(s.key, accounts.TextHash(data))
+}
+
+// SignTypedData signs EIP-712 typed data as account_signTypedData of clef
+func (s *standInSigner) SignTypedData(addr common.MixedcaseAddress, data json.RawMessage) (hexutil.Bytes, error) {
+	if addr.Address() != crypto.PubkeyToAddress(s.key.PublicKey) {
+		return nil, fmt.Errorf("unknown account %v", addr.Address().String())
+	}
+	td, err := ParseTypedData(data)
+	if err != nil {
+		return nil, err
+	}
+	if s.tamper {
+		td.PrimaryType = eip712DomainType
+	}
+	hash, err := td.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return signHash(s.key, hash.Bytes())
+}
+
+func newStandInClient(t *testing.T, standIn *standInSigner) *rpc.Client {
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", standIn); err != nil {
+		t.Fatal(err)
+	}
+	return rpc.DialInProc(server)
+}
+
+func TestExternalSigner(t *testing.T) {
+	privK, err := crypto.HexToECDSA(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(privK.PublicKey)
+	client := newStandInClient(t, &standInSigner{key: privK})
+	defer client.Close()
+
+	signer := NewExternalSignerWithClient(client, address.String())
+	accounts, err := signer.Accounts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0] != address {
+		t.Fatalf("accounts: %v", accounts)
+	}
+	checkSigner(t, signer, address)
+
+	other := NewExternalSignerWithClient(client, "0x00000000000000000000000000000000000000bb")
+	if _, err := other.SignTx(context.Background(), types.NewLondonSigner(big.NewInt(5)), testTxs(big.NewInt(5))[0]); err == nil {
+		t.Fatal("expect error of unknown account")
+	}
+}
+
+func TestExternalSignerTampered(t *testing.T) {
+	privK, err := crypto.HexToECDSA(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newStandInClient(t, &standInSigner{key: privK, tamper: true})
+	defer client.Close()
+
+	signer := NewExternalSignerWithClient(client, crypto.PubkeyToAddress(privK.PublicKey).String())
+	_, err = signer.SignTx(context.Background(), types.NewLondonSigner(big.NewInt(5)), testTxs(big.NewInt(5))[0])
+	if err == nil || !strings.Contains(err.Error(), "different transaction") {
+		t.Fatalf("expect error of different transaction, got %v", err)
+	}
+}
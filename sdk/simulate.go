@@ -0,0 +1,84 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type simulationKey struct{}
+
+// WithSimulation returns ctx making SendTx and its callers (WriteContract, Transfer20, TransferFrom721...) simulate tx
+// before signing it: tx is refused with *RevertError when eth_call of it at pending block reverts,
+// or with ErrInsufficientFunds when balance of sender can't pay value + gasLimit * fee
+// e.g. tm.Transfer20Context(sdk.WithSimulation(ctx), ...)
+func WithSimulation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, simulationKey{}, true)
+}
+
+func simulation(ctx context.Context) bool {
+	simulate, _ := ctx.Value(simulationKey{}).(bool)
+	return simulate
+}
+
+// SimulateTx simulates tx from fromAddr to toAddr at pending block as SendTx does with WithSimulation
+// set toAddr to empty string for contract creation, gasPrice is the max fee per gas the tx would pay
+// error is *RevertError when the tx would revert, ErrInsufficientFunds when fromAddr can't pay it
+func (tm *TransactionManager) SimulateTx(fromAddr string, toAddr string, value *big.Int, data []byte, gasLimit uint64, gasPrice *big.Int) error {
+	return tm.SimulateTxContext(context.Background(), fromAddr, toAddr, value, data, gasLimit, gasPrice)
+}
+
+// SimulateTxContext is like SimulateTx but with ctx for cancellation and deadline
+func (tm *TransactionManager) SimulateTxContext(ctx context.Context, fromAddr string, toAddr string, value *big.Int, data []byte, gasLimit uint64, gasPrice *big.Int) error {
+	var to *common.Address
+	if toAddr != "" {
+		toAddress := common.HexToAddress(toAddr)
+		to = &toAddress
+	}
+	if gasPrice == nil {
+		gasPrice = new(big.Int)
+	}
+	tx := types.NewTx(&types.LegacyTx{To: to, Value: value, Data: data, Gas: gasLimit, GasPrice: gasPrice})
+	return tm.simulate(ctx, common.HexToAddress(fromAddr), tx, "")
+}
+
+// simulate calls unsigned tx from at pending block and checks balance of from covers its cost,
+// custom errors declared in abiStr are decoded, set it to empty string when there is no abi
+func (tm *TransactionManager) simulate(ctx context.Context, from common.Address, tx *types.Transaction, abiStr string) error {
+	msg := ethereum.CallMsg{
+		From:       from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+	err := tm.retry.Do(ctx, func() error {
+		_, err := tm.Client.PendingCallContract(ctx, msg)
+		return err
+	})
+	if err != nil {
+		if revertErr := newRevertError(err, abiStr); revertErr != nil {
+			return revertErr
+		}
+		return classifyNodeError(fmt.Errorf("simulate tx error: %w", err))
+	}
+
+	var balance *big.Int
+	err = tm.retry.Do(ctx, func() (err error) {
+		balance, err = tm.Client.PendingBalanceAt(ctx, from)
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("PendingBalanceAt() error: %s", err.Error())
+	}
+	// gas fee cap is gas price of legacy tx
+	if cost := tx.Cost(); balance.Cmp(cost) < 0 {
+		return fmt.Errorf("%w: balance of %v is %v, tx costs %v", ErrInsufficientFunds, from.String(), balance, cost)
+	}
+	return nil
+}
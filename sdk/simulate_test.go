@@ -0,0 +1,105 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSimulation(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.close()
+	tm, closeFn := backend.newTM(t)
+	defer closeFn()
+	tm.gasLimit = 1e5
+
+	// calls whose last byte of data is 0xff, such as transfer of 255, revert with Error("bad recipient"),
+	// and 0xfe with InsufficientBalance(1, 100) of customErrorABI
+	uint256, _ := abi.NewType("uint256", "", nil)
+	custom, err := (abi.Arguments{{Type: uint256}, {Type: uint256}}).Pack(big.NewInt(1), big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom = append((&abiError{Name: "InsufficientBalance", Inputs: abi.Arguments{{Type: uint256}, {Type: uint256}}}).selector(), custom...)
+	var blocks []string
+	backend.set(func(b *testBackend) {
+		b.call = func(args testCallArgs, block string) ([]byte, error) {
+			blocks = append(blocks, block)
+			switch {
+			case len(args.Data) == 0:
+			case args.Data[len(args.Data)-1] == 0xff:
+				return nil, testRevert{encodeRevert(t, errorSelector, "string", "bad recipient")}
+			case args.Data[len(args.Data)-1] == 0xfe:
+				return nil, testRevert{custom}
+			}
+			return common.LeftPadBytes([]byte{1}, 32), nil
+		}
+		b.balance = func(address common.Address, block string) *big.Int {
+			blocks = append(blocks, block)
+			return big.NewInt(1e18)
+		}
+	})
+
+	signer, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := testAddress(0xaa)
+	ctx := WithSimulation(context.Background())
+
+	// reverted call is not sent
+	_, err = tm.Transfer20Context(ctx, token, signer, testAddress(1), "255", nil, 0, 0)
+	var revertErr *RevertError
+	if !errors.As(err, &revertErr) || revertErr.Reason != "bad recipient" {
+		t.Fatalf("expect revert, got %v", err)
+	}
+	_, err = tm.TransferFrom721Context(ctx, token, signer, signer.Address().String(), testAddress(1), "255", nil, 0, 0)
+	if !errors.Is(err, ErrReverted) {
+		t.Fatalf("expect revert, got %v", err)
+	}
+	// custom error of abi of WriteContract is decoded
+	_, err = tm.WriteContractContext(ctx, signer, token, nil, customErrorABI, "transfer", "address:"+testAddress(1)+";uint256:254", nil, 0, 0)
+	if !errors.As(err, &revertErr) || revertErr.Reason != "InsufficientBalance(1, 100)" {
+		t.Fatalf("expect custom error, got %v", err)
+	}
+	if n := backend.count("eth_sendRawTransaction"); n != 0 {
+		t.Fatalf("%v transactions are sent", n)
+	}
+
+	// successful call is sent
+	hash, err := tm.Transfer20Context(ctx, token, signer, testAddress(1), "1", nil, 0, 0)
+	if err != nil || backend.lastSent(t).Hash().String() != hash {
+		t.Fatalf("send: %v %v", hash, err)
+	}
+	backend.set(func(b *testBackend) {
+		for _, block := range blocks {
+			if block != "pending" {
+				t.Fatalf("simulation at block %v", block)
+			}
+		}
+	})
+
+	// balance can't pay value + gasLimit * fee
+	value := new(big.Int).Sub(big.NewInt(1e18), big.NewInt(21000*1e9-1))
+	if _, err := tm.TransferEthContext(ctx, signer, testAddress(1), value, nil, 0); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expect ErrInsufficientFunds, got %v", err)
+	}
+	if err := tm.SimulateTx(signer.Address().String(), testAddress(1), value, nil, 20000, big.NewInt(1e9)); err != nil {
+		t.Fatal(err)
+	}
+	if n := backend.count("eth_sendRawTransaction"); n != 1 {
+		t.Fatalf("%v transactions are sent", n)
+	}
+
+	// without simulation the tx is sent as before
+	if _, err := tm.Transfer20(token, signer, testAddress(1), "255", nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if n := backend.count("eth_sendRawTransaction"); n != 2 {
+		t.Fatalf("%v transactions are sent", n)
+	}
+}
@@ -0,0 +1,134 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// signed transactions kept for re-broadcasting
+	maxSentTxs = 4096
+)
+
+// TxEventType is the type of TxEvent
+type TxEventType int
+
+const (
+	// TxMined tx is seen in a block for the first time
+	TxMined TxEventType = iota
+	// TxDropped the block of tx is reorganised away, tx is not in canonical chain now
+	TxDropped
+	// TxReincluded tx is included in a different block after reorg
+	TxReincluded
+	// TxConfirmed tx reached the waited confirmations
+	TxConfirmed
+)
+
+func (t TxEventType) String() string {
+	switch t {
+	case TxMined:
+		return "mined"
+	case TxDropped:
+		return "dropped"
+	case TxReincluded:
+		return "reincluded"
+	case TxConfirmed:
+		return "confirmed"
+	default:
+		return fmt.Sprintf("TxEventType(%d)", int(t))
+	}
+}
+
+// TxEvent reports a change of a waited or tracked tx, Receipt is nil for TxDropped
+type TxEvent struct {
+	Type    TxEventType
+	Hash    common.Hash
+	Receipt *types.Receipt
+}
+
+// sentTxs keeps recent signed transactions sent by TransactionManager
+type sentTxs struct {
+	mu    sync.Mutex
+	txs   map[common.Hash]*types.Transaction
+	order []common.Hash
+}
+
+func (s *sentTxs) add(tx *types.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.txs == nil {
+		s.txs = make(map[common.Hash]*types.Transaction)
+	}
+	if _, ok := s.txs[tx.Hash()]; ok {
+		return
+	}
+	s.txs[tx.Hash()] = tx
+	s.order = append(s.order, tx.Hash())
+	if len(s.order) > maxSentTxs {
+		delete(s.txs, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+func (s *sentTxs) get(hash common.Hash) *types.Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.txs[hash]
+}
+
+// isAlreadyKnown reports whether err is node's error of sending a tx already in its pool
+func isAlreadyKnown(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "already known") || strings.Contains(err.Error(), "known transaction"))
+}
+
+// SubscribeTxEvents subscribes mined, dropped, re-included and confirmed events
+// of transactions waited by *Sync helpers, WaitReceipt(s) and Track
+// events are buffered for each subscriber and dropped when ch is not read in time, they never block waits
+func (tm *TransactionManager) SubscribeTxEvents(ch chan<- TxEvent) event.Subscription {
+	return tm.waiter.subscribe(ch)
+}
+
+// Track watches hash in background until it's mined with confirmations blocks or tm.timeout seconds passed,
+// changes of it are reported to SubscribeTxEvents, and it's re-broadcast when reorganised away
+// transactions waited by the *Sync helpers are tracked the same way to the reorg depth, see SetReorgDepth
+func (tm *TransactionManager) Track(hash string, confirmations uint64) {
+	rw := newReceiptWait([]common.Hash{common.HexToHash(hash)}, confirmations)
+	tm.waiter.follow(rw, time.Second*time.Duration(tm.timeout))
+}
+
+// Rebroadcast sends signed tx of hash sent by tm again, it's a no-op when node already knows the tx
+func (tm *TransactionManager) Rebroadcast(hash string) error {
+	return tm.RebroadcastContext(context.Background(), hash)
+}
+
+// RebroadcastContext is like Rebroadcast but with ctx for cancellation and deadline
+func (tm *TransactionManager) RebroadcastContext(ctx context.Context, hash string) error {
+	tx := tm.sent.get(common.HexToHash(hash))
+	if tx == nil {
+		return fmt.Errorf("signed transaction %v is unknown", hash)
+	}
+	err := sendSignedTx(ctx, tm.retry, tm.Client, tx)
+	if isAlreadyKnown(err) {
+		return nil
+	}
+	return err
+}
+
+// rebroadcast is called by waiter when tx of hash is dropped by reorg
+func (tm *TransactionManager) rebroadcast(ctx context.Context, hash common.Hash) {
+	if err := tm.RebroadcastContext(ctx, hash.String()); err != nil {
+		logrus.Errorf("re-broadcast transaction %v error: %v", hash.String(), err)
+		return
+	}
+	logrus.Infof("transaction %v is re-broadcast", hash.String())
+}
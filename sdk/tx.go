@@ -10,7 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/sirupsen/logrus"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 const (
@@ -19,8 +19,10 @@ const (
 	// gasLimit    = 2.3e4
 )
 
-func dial(rpcURL string) (*ethclient.Client, error) {
-	ctx, _ := context.WithTimeout(context.Background(), time.Second*time.Duration(dialTimeout))
+// dialContext dials rpcURL, ctx with dialTimeout limits the dial only
+func dialContext(ctx context.Context, rpcURL string) (*ethclient.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(dialTimeout))
+	defer cancel()
 	client, err := ethclient.DialContext(ctx, rpcURL)
 	if err != nil {
 		return nil, err
@@ -28,59 +30,92 @@ func dial(rpcURL string) (*ethclient.Client, error) {
 	return client, nil
 }
 
+func dialRPCContext(ctx context.Context, rpcURL string) (*rpc.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(dialTimeout))
+	defer cancel()
+	client, err := rpc.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
 // SendTx sends generic transaction to chain, including 'transfer eth' 'create contract'(when toAddr is empty string) 'call contract',not including 'call msg tx'
-// set gasPrice to 0 to use suggest gas price
-func SendTx(rpcURL string, fromSK string, toAddr string, value uint64, data []byte, gasPrice uint64, gasLimit uint64) (common.Hash, error) {
-	client, err := dial(rpcURL)
+// it sends legacy transactions signed with chain id of node (EIP155), use TransactionManager for EIP-1559 transactions
+// set gasPrice to nil to use suggest gas price
+func SendTx(rpcURL string, signer Signer, toAddr string, value uint64, data []byte, gasPrice *big.Int, gasLimit uint64) (common.Hash, error) {
+	return SendTxContext(context.Background(), rpcURL, signer, toAddr, value, data, gasPrice, gasLimit)
+}
+
+// SendTxContext is like SendTx but with ctx for cancellation and deadline
+func SendTxContext(ctx context.Context, rpcURL string, signer Signer, toAddr string, value uint64, data []byte, gasPrice *big.Int, gasLimit uint64) (common.Hash, error) {
+	client, err := dialContext(ctx, rpcURL)
 	if err != nil {
 		return common.Hash{}, err
 	}
 	defer client.Close()
 
-	privK, _, fromAddress, err := HexToAccount(fromSK)
+	retry := packageRetryPolicy()
+	var chainID *big.Int
+	err = retry.Do(ctx, func() (err error) {
+		chainID, err = client.ChainID(ctx)
+		return
+	})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("ChainID() error: %s", err.Error())
+	}
+	// a TransactionManager over client only builds and signs the tx
+	tm := &TransactionManager{
+		Client:           client,
+		chainID:          chainID.String(),
+		eip155:           true,
+		gasPriceStrategy: NewNodeGasPrice(client, 0),
+	}
+	txSigner, err := tm.txSigner()
 	if err != nil {
-		logrus.Errorf("convert hex sk to ECDSA error: %v", err)
 		return common.Hash{}, err
 	}
-	var toAddress common.Address
+
+	fromAddress := signer.Address()
+	var to *common.Address
 	if toAddr != "" {
-		toAddress = common.HexToAddress(toAddr)
+		toAddress := common.HexToAddress(toAddr)
+		to = &toAddress
 	}
 	//nonce
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	var nonce uint64
+	err = retry.Do(ctx, func() (err error) {
+		nonce, err = client.PendingNonceAt(ctx, fromAddress)
+		return
+	})
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("PendingNonceAt() error: %s", err.Error())
 	}
 
-	//gas price
-	if gasPrice == 0 {
-		suggestGasPrice, err := client.SuggestGasPrice(context.Background())
-		if err != nil {
-			return common.Hash{}, fmt.Errorf("SuggestGasPrice() error: %s", err.Error())
-		}
-		gasPrice = suggestGasPrice.Uint64()
-	}
-	price := new(big.Int)
-	price.SetUint64(gasPrice)
-
 	// value
 	val := new(big.Int)
 	val.SetUint64(value)
 
-	var tx *types.Transaction
-	if toAddr != "" {
-		tx = types.NewTransaction(nonce, toAddress, val, gasLimit, price, data)
-	} else {
-		tx = types.NewContractCreation(nonce, val, gasLimit, price, data)
+	txData, err := tm.newTxData(ctx, to, val, data, nil, gasPrice, nonce, gasLimit)
+	if err != nil {
+		return common.Hash{}, err
 	}
-	signedTx, err := types.SignTx(tx, types.HomesteadSigner{}, privK)
-	return signedTx.Hash(), client.SendTransaction(context.Background(), signedTx)
+	signedTx, err := signer.SignTx(ctx, txSigner, types.NewTx(txData))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("sign tx error: %s", err.Error())
+	}
+	return signedTx.Hash(), sendSignedTx(ctx, retry, client, signedTx)
 }
 
 // SendCallMsgTx calls readonly contract functions on local node
-// set gasPrice to 0 to use suggest gas price
-func SendCallMsgTx(rpcURL string, fromAddr string, toAddr string, data []byte, gasPrice uint64, gasLimit uint64) ([]byte, error) {
-	client, err := dial(rpcURL)
+// set gasPrice to nil to use suggest gas price
+func SendCallMsgTx(rpcURL string, fromAddr string, toAddr string, data []byte, gasPrice *big.Int, gasLimit uint64) ([]byte, error) {
+	return SendCallMsgTxContext(context.Background(), rpcURL, fromAddr, toAddr, data, gasPrice, gasLimit)
+}
+
+// SendCallMsgTxContext is like SendCallMsgTx but with ctx for cancellation and deadline
+func SendCallMsgTxContext(ctx context.Context, rpcURL string, fromAddr string, toAddr string, data []byte, gasPrice *big.Int, gasLimit uint64) ([]byte, error) {
+	client, err := dialContext(ctx, rpcURL)
 	if err != nil {
 		return nil, err
 	}
@@ -93,15 +128,13 @@ func SendCallMsgTx(rpcURL string, fromAddr string, toAddr string, data []byte, g
 	v.SetUint64(uint64(0))
 
 	//gas price
-	if gasPrice == 0 {
-		suggestGasPrice, err := client.SuggestGasPrice(context.Background())
+	price := gasPrice
+	if price == nil {
+		price, err = client.SuggestGasPrice(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("SuggestGasPrice() error: %s", err.Error())
 		}
-		gasPrice = suggestGasPrice.Uint64()
 	}
-	price := new(big.Int)
-	price.SetUint64(gasPrice)
 
 	msg := ethereum.CallMsg{
 		From:     from,
@@ -111,37 +144,62 @@ func SendCallMsgTx(rpcURL string, fromAddr string, toAddr string, data []byte, g
 		Value:    v,
 		Data:     data,
 	}
-	return client.CallContract(context.Background(), msg, nil)
+	var output []byte
+	err = packageRetryPolicy().Do(ctx, func() (err error) {
+		output, err = client.CallContract(ctx, msg, nil)
+		return
+	})
+	return output, err
 }
 
 // GetBalance query balance of address
 func GetBalance(rpcURL string, address string) (*big.Int, error) {
-	client, err := dial(rpcURL)
+	return GetBalanceContext(context.Background(), rpcURL, address)
+}
+
+// GetBalanceContext is like GetBalance but with ctx for cancellation and deadline
+func GetBalanceContext(ctx context.Context, rpcURL string, address string) (*big.Int, error) {
+	client, err := dialContext(ctx, rpcURL)
 	if err != nil {
 		return nil, err
 	}
 	defer client.Close()
 
 	addr := common.HexToAddress(address)
-	return client.BalanceAt(context.Background(), addr, nil)
+	var balance *big.Int
+	err = packageRetryPolicy().Do(ctx, func() (err error) {
+		balance, err = client.BalanceAt(ctx, addr, nil)
+		return
+	})
+	return balance, err
+}
+
+// CreateContract create smart contract with signer and data
+// set gasPrice to nil to use suggest gas price
+func CreateContract(rpcURL string, signer Signer, data []byte, gasPrice *big.Int, gasLimit uint64) (common.Hash, error) {
+	return CreateContractContext(context.Background(), rpcURL, signer, data, gasPrice, gasLimit)
 }
 
-// CreateContract create smart contract with sk and data
-// set gasPrice to 0 to use suggest gas price
-func CreateContract(rpcURL string, sk string, data []byte, gasPrice uint64, gasLimit uint64) (common.Hash, error) {
-	return SendTx(rpcURL, sk, "", 0, data, gasPrice, gasLimit)
+// CreateContractContext is like CreateContract but with ctx for cancellation and deadline
+func CreateContractContext(ctx context.Context, rpcURL string, signer Signer, data []byte, gasPrice *big.Int, gasLimit uint64) (common.Hash, error) {
+	return SendTxContext(ctx, rpcURL, signer, "", 0, data, gasPrice, gasLimit)
 }
 
 // GetTransactionUsedGas get used gas of a transaction
 func GetTransactionUsedGas(rpcURL string, hash string) (uint64, error) {
-	client, err := dial(rpcURL)
+	return GetTransactionUsedGasContext(context.Background(), rpcURL, hash)
+}
+
+// GetTransactionUsedGasContext is like GetTransactionUsedGas but with ctx for cancellation and deadline
+func GetTransactionUsedGasContext(ctx context.Context, rpcURL string, hash string) (uint64, error) {
+	client, err := dialContext(ctx, rpcURL)
 	if err != nil {
 		return 0, err
 	}
 	defer client.Close()
 
 	h := common.HexToHash(hash)
-	receipt, err := client.TransactionReceipt(context.Background(), h)
+	receipt, err := client.TransactionReceipt(ctx, h)
 	if err != nil {
 		return 0, err
 	}
@@ -150,14 +208,19 @@ func GetTransactionUsedGas(rpcURL string, hash string) (uint64, error) {
 
 // GetContractAddress query address of contract by it's hash
 func GetContractAddress(rpcURL string, hash string) (string, error) {
-	client, err := dial(rpcURL)
+	return GetContractAddressContext(context.Background(), rpcURL, hash)
+}
+
+// GetContractAddressContext is like GetContractAddress but with ctx for cancellation and deadline
+func GetContractAddressContext(ctx context.Context, rpcURL string, hash string) (string, error) {
+	client, err := dialContext(ctx, rpcURL)
 	if err != nil {
 		return "", err
 	}
 	defer client.Close()
 
 	h := common.HexToHash(hash)
-	receipt, err := client.TransactionReceipt(context.Background(), h)
+	receipt, err := client.TransactionReceipt(ctx, h)
 	if err != nil {
 		return "", err
 	}
@@ -165,13 +228,18 @@ func GetContractAddress(rpcURL string, hash string) (string, error) {
 }
 
 // WriteContract calls writable function of contract
-// set gasPrice to 0 to use suggest gas price
-func WriteContract(rpcURL string, sk string, contractAddress string, abi string, methodName, args string, gasPrice uint64, gasLimit uint64) (string, error) {
+// set gasPrice to nil to use suggest gas price
+func WriteContract(rpcURL string, signer Signer, contractAddress string, abi string, methodName, args string, gasPrice *big.Int, gasLimit uint64) (string, error) {
+	return WriteContractContext(context.Background(), rpcURL, signer, contractAddress, abi, methodName, args, gasPrice, gasLimit)
+}
+
+// WriteContractContext is like WriteContract but with ctx for cancellation and deadline
+func WriteContractContext(ctx context.Context, rpcURL string, signer Signer, contractAddress string, abi string, methodName, args string, gasPrice *big.Int, gasLimit uint64) (string, error) {
 	payload, err := Pack(abi, methodName, args)
 	if err != nil {
 		return "", err
 	}
-	hash, err := SendTx(rpcURL, sk, contractAddress, 0, payload, gasPrice, gasLimit)
+	hash, err := SendTxContext(ctx, rpcURL, signer, contractAddress, 0, payload, gasPrice, gasLimit)
 	if err != nil {
 		return "", err
 	}
@@ -179,13 +247,18 @@ func WriteContract(rpcURL string, sk string, contractAddress string, abi string,
 }
 
 // ReadContract calls readonly function of contract
-// set gasPrice to 0 to use suggest gas price
-func ReadContract(rpcURL string, fromAddr string, contractAddress string, abi string, methodName, args string, gasPrice uint64, gasLimit uint64) ([]byte, error) {
+// set gasPrice to nil to use suggest gas price
+func ReadContract(rpcURL string, fromAddr string, contractAddress string, abi string, methodName, args string, gasPrice *big.Int, gasLimit uint64) ([]byte, error) {
+	return ReadContractContext(context.Background(), rpcURL, fromAddr, contractAddress, abi, methodName, args, gasPrice, gasLimit)
+}
+
+// ReadContractContext is like ReadContract but with ctx for cancellation and deadline
+func ReadContractContext(ctx context.Context, rpcURL string, fromAddr string, contractAddress string, abi string, methodName, args string, gasPrice *big.Int, gasLimit uint64) ([]byte, error) {
 	payload, err := Pack(abi, methodName, args)
 	if err != nil {
 		return nil, err
 	}
-	output, err := SendCallMsgTx(rpcURL, fromAddr, contractAddress, payload, gasPrice, gasLimit)
+	output, err := SendCallMsgTxContext(ctx, rpcURL, fromAddr, contractAddress, payload, gasPrice, gasLimit)
 	if err != nil {
 		return nil, err
 	}
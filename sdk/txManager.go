@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 const (
@@ -20,58 +23,111 @@ const (
 // TransactionManager store info to operate tx
 type TransactionManager struct {
 	rpcURL   string
-	gasPrice uint64 // default gas price
 	gasLimit uint64 // default gas limit
 	timeout  uint64
 	interval uint64
 	*ethclient.Client
-	chainID string
-	eip155  bool
+	rpcClient    *rpc.Client
+	gethClient   *gethclient.Client
+	endpointPool *EndpointPool // not nil when made by NewFailover
+	chainID      string
+	eip155       bool
+
+	gasPriceStrategy GasPriceStrategy // decides gas price and tip when they are nil
+
+	dynamicFee bool     // send EIP-1559 (type 2) transactions
+	gasTipCap  *big.Int // default max priority fee per gas, nil to use tip of gasPriceStrategy
+
+	nonceManager *NonceManager // hands out nonces locally when not nil
+	replacements replacements  // transactions replaced by SpeedUp or Cancel
+
+	estimateGas   bool    // estimate gas limit when it's 0
+	gasMultiplier float64 // estimated gas is scaled by it
+	gasLimitCap   uint64  // estimated gas limit is clamped to it, 0 for block gas limit
+
+	waiter        *receiptWaiter
+	confirmations uint64  // blocks the *Sync helpers wait for
+	reorgDepth    uint64  // blocks a tx is tracked for after its wait returned
+	sent          sentTxs // recent signed transactions for re-broadcasting
+
+	retry     *RetryPolicy // retries transient RPC failures, nil for no retry
+	batchSize int          // requests in a JSON-RPC batch of Batch* helpers
+
+	multicallMu      sync.Mutex
+	multicallAddress string // aggregator of Multicall, empty for Multicall3Address
+
+	journalMu       sync.Mutex
+	journal         *Journal // signed transactions are written to it before broadcast when not nil
+	journalCtx      context.Context
+	journalCancel   context.CancelFunc // stops watchers of journal
+	journalWatchers *sync.WaitGroup
 }
 
 // New makes a new TransactionManager
 /*
-** if gasPrice is 0, use suggest gas price
+** if gasPrice is nil, use suggest gas price of node, refreshed every minute
+** otherwise legacy transactions use gasPrice, and tip of EIP-1559 transactions is suggested by node
 ** if timeout is 0, use default timeout
 ** if interval is 0, use default interval
  */
-func New(rpcURL string, gasPrice, gasLimit, timeout, interval uint64) (*TransactionManager, error) {
-	var err error
+func New(rpcURL string, gasPrice *big.Int, gasLimit, timeout, interval uint64) (*TransactionManager, error) {
+	return NewContext(context.Background(), rpcURL, gasPrice, gasLimit, timeout, interval)
+}
+
+// NewContext is like New but with ctx for cancellation and deadline
+func NewContext(ctx context.Context, rpcURL string, gasPrice *big.Int, gasLimit, timeout, interval uint64) (*TransactionManager, error) {
+	rpcClient, err := dialRPCContext(ctx, rpcURL)
+	if err != nil {
+		return nil, err
+	}
 	tm := &TransactionManager{
 		rpcURL:   rpcURL,
-		gasPrice: gasPrice,
 		gasLimit: gasLimit,
 		timeout:  timeout,
 		interval: interval,
 		eip155:   true,
 	}
-	tm.Client, err = dial(tm.rpcURL)
-	if err != nil {
+	if err := tm.init(ctx, rpcClient, gasPrice); err != nil {
+		tm.Close()
 		return nil, err
 	}
-	if gasPrice == 0 {
-		sgp, err := tm.Client.SuggestGasPrice(context.Background())
-		if err != nil {
-			return nil, fmt.Errorf("Get suggest gas price error: %s", err.Error())
+	return tm, nil
+}
+
+// init sets up clients over rpcClient, gas price strategy, chain id and defaults
+func (tm *TransactionManager) init(ctx context.Context, rpcClient *rpc.Client, gasPrice *big.Int) error {
+	tm.rpcClient = rpcClient
+	tm.Client = ethclient.NewClient(tm.rpcClient)
+	tm.gethClient = gethclient.New(tm.rpcClient)
+	if gasPrice == nil {
+		tm.gasPriceStrategy = NewNodeGasPrice(tm.Client, defaultGasPriceRefresh)
+		if _, err := tm.gasPriceStrategy.GasPrice(ctx); err != nil {
+			return fmt.Errorf("Get suggest gas price error: %s", err.Error())
 		}
-		tm.gasPrice = sgp.Uint64()
+	} else {
+		tm.gasPriceStrategy = NewFixedGasPrice(gasPrice, nil)
 	}
 
-	chainID, err := tm.Client.ChainID(context.Background())
+	chainID, err := tm.Client.ChainID(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	tm.chainID = chainID.String()
 
-	if timeout == 0 {
+	if tm.timeout == 0 {
 		tm.timeout = defaultTimeout
 	}
-	if interval == 0 {
+	if tm.interval == 0 {
 		tm.interval = defaultInterval
 	}
+	tm.confirmations = defaultConfirmations
+	tm.reorgDepth = defaultReorgDepth
+	tm.waiter = newReceiptWaiter(tm.Client, time.Second*time.Duration(tm.interval))
+	tm.waiter.rebroadcast = tm.rebroadcast
+	tm.SetRetryPolicy(DefaultRetryPolicy())
 
-	return tm, nil
+	return nil
 }
 
 // // set chain id for EIP155
@@ -80,163 +136,387 @@ func New(rpcURL string, gasPrice, gasLimit, timeout, interval uint64) (*Transact
 // }
 
 func (tm *TransactionManager) Close() {
-	tm.Client.Close()
+	tm.rpcClient.Close()
+	if tm.endpointPool != nil {
+		tm.endpointPool.Close()
+	}
+	tm.stopJournal()
 }
 
-func (tm *TransactionManager) GasPrice() uint64 {
-	return tm.gasPrice
+// GasPrice returns current gas price of tm's gas price strategy
+func (tm *TransactionManager) GasPrice() (*big.Int, error) {
+	return tm.GasPriceContext(context.Background())
+}
+
+// GasPriceContext is like GasPrice but with ctx for cancellation and deadline
+func (tm *TransactionManager) GasPriceContext(ctx context.Context) (*big.Int, error) {
+	return tm.gasPriceStrategy.GasPrice(ctx)
 }
 
 func (tm *TransactionManager) DisableEIP155() {
 	tm.eip155 = false
 }
 
-// SendTx sends an async tx, and return tx's hash
-// pass gasPrice to 0 to use tm.gasPrice
-// pass nonce to 0 to use pendingNonce
-// pass gasLimit to 0 to use tm.gasLimit
-func (tm *TransactionManager) SendTx(fromSK string, toAddr string, value *big.Int, data []byte, gasPrice uint64, nonce uint64, gasLimit uint64) (string, error) {
-	privK, _, fromAddress, err := HexToAccount(fromSK)
+// EnableDynamicFee makes SendTx build EIP-1559 (type 2) transactions signed with London signer
+// in this mode, gasPrice of SendTx and its callers is used as maxFeePerGas
+func (tm *TransactionManager) EnableDynamicFee() {
+	tm.dynamicFee = true
+}
+
+// SetGasTipCap sets default maxPriorityFeePerGas of EIP-1559 transactions
+// set tip to nil to use tip of gas price strategy
+func (tm *TransactionManager) SetGasTipCap(tip *big.Int) {
+	tm.gasTipCap = tip
+}
+
+// EnableNonceManager makes SendTx take nonces from a local NonceManager instead of PendingNonceAt,
+// so concurrent senders with the same key get different nonces in order
+func (tm *TransactionManager) EnableNonceManager() {
+	if tm.nonceManager == nil {
+		tm.nonceManager = NewNonceManager(tm.Client)
+	}
+}
+
+// NonceManager returns local nonce manager, nil when it's not enabled
+func (tm *TransactionManager) NonceManager() *NonceManager {
+	return tm.nonceManager
+}
+
+// txSigner returns signer matching the transaction type sent by tm
+func (tm *TransactionManager) txSigner() (types.Signer, error) {
+	if !tm.eip155 {
+		if tm.dynamicFee {
+			return nil, fmt.Errorf("EIP-1559 transaction requires EIP155")
+		}
+		return types.HomesteadSigner{}, nil
+	}
+	chainId, success := big.NewInt(0).SetString(tm.chainID, 10)
+	if !success {
+		return nil, ErrInvalidChainID
+	}
+	// London signer signs legacy transactions as EIP155 and also supports typed transactions
+	return types.NewLondonSigner(chainId), nil
+}
+
+// gasTipCapOf returns maxPriorityFeePerGas of EIP-1559 transaction: tm.gasTipCap, tip of gas price strategy,
+// or tip suggested by node when the strategy has none
+func (tm *TransactionManager) gasTipCapOf(ctx context.Context) (*big.Int, error) {
+	if tm.gasTipCap != nil {
+		return new(big.Int).Set(tm.gasTipCap), nil
+	}
+	tip, err := tm.gasPriceStrategy.GasTipCap(ctx)
 	if err != nil {
-		return "", fmt.Errorf("convert hex sk to ECDSA error: %s", err.Error())
+		return nil, err
+	}
+	if tip != nil {
+		return tip, nil
 	}
-	var toAddress common.Address
+	tip, err = tm.Client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("SuggestGasTipCap() error: %s", err.Error())
+	}
+	return tip, nil
+}
+
+// dynamicFees returns maxPriorityFeePerGas and maxFeePerGas of EIP-1559 transaction
+// when gasFeeCap is nil, maxFeePerGas is 2 * baseFee + maxPriorityFeePerGas
+func (tm *TransactionManager) dynamicFees(ctx context.Context, gasFeeCap *big.Int) (*big.Int, *big.Int, error) {
+	tip, err := tm.gasTipCapOf(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if gasFeeCap != nil {
+		feeCap := new(big.Int).Set(gasFeeCap)
+		// tip can not be greater than fee cap
+		if tip.Cmp(feeCap) > 0 {
+			tip = new(big.Int).Set(feeCap)
+		}
+		return tip, feeCap, nil
+	}
+
+	head, err := tm.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("HeaderByNumber() error: %s", err.Error())
+	}
+	if head.BaseFee == nil {
+		return nil, nil, fmt.Errorf("chain does not support EIP-1559: no base fee in latest block")
+	}
+	feeCap := new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+	return tip, feeCap, nil
+}
+
+// SendTx sends an async tx, and return tx's hash
+// pass gasPrice to nil to use price of tm's gas price strategy
+// when dynamic fee is enabled, gasPrice is maxFeePerGas, pass it to nil to use 2 * baseFee + maxPriorityFeePerGas
+// pass nonce to 0 to use pendingNonce
+// pass gasLimit to 0 to use tm.gasLimit, or estimated gas limit when gas estimation is enabled
+func (tm *TransactionManager) SendTx(signer Signer, toAddr string, value *big.Int, data []byte, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, error) {
+	return tm.SendTxContext(context.Background(), signer, toAddr, value, data, gasPrice, nonce, gasLimit)
+}
+
+// SendTxContext is like SendTx but with ctx for cancellation and deadline
+func (tm *TransactionManager) SendTxContext(ctx context.Context, signer Signer, toAddr string, value *big.Int, data []byte, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, error) {
+	return tm.sendTx(ctx, signer, toAddr, value, data, nil, "", gasPrice, nonce, gasLimit)
+}
+
+// sendTx signs and sends tx, accessList is attached when not nil
+// legacy tx with access list is sent as EIP-2930 (type 1) transaction
+// custom errors declared in abiStr are decoded when estimation or simulation reverts, empty string for no abi
+func (tm *TransactionManager) sendTx(ctx context.Context, signer Signer, toAddr string, value *big.Int, data []byte, accessList types.AccessList, abiStr string, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, error) {
+	fromAddress := signer.Address()
+	var to *common.Address
 	if toAddr != "" {
-		toAddress = common.HexToAddress(toAddr)
+		toAddress := common.HexToAddress(toAddr)
+		to = &toAddress
+	}
+	txSigner, err := tm.txSigner()
+	if err != nil {
+		return "", err
 	}
-	if nonce == 0 {
-		nonce, err = tm.Client.PendingNonceAt(context.Background(), fromAddress)
+	if gasLimit == 0 && tm.estimateGas {
+		gasLimit, err = tm.estimateGasLimit(ctx, fromAddress, to, value, data, accessList, abiStr)
 		if err != nil {
-			return "", fmt.Errorf("PendingNonceAt() error: %s", err.Error())
+			return "", err
 		}
 	}
 
-	price := new(big.Int)
-	if gasPrice == 0 {
-		gasPrice = tm.gasPrice
+	managed := nonce == 0 && tm.nonceManager != nil
+	resynced := false
+	for {
+		if managed {
+			nonce, err = tm.nonceManager.Acquire(ctx, fromAddress)
+			if err != nil {
+				return "", err
+			}
+		} else if nonce == 0 {
+			err = tm.retry.Do(ctx, func() (err error) {
+				nonce, err = tm.Client.PendingNonceAt(ctx, fromAddress)
+				return
+			})
+			if err != nil {
+				return "", fmt.Errorf("PendingNonceAt() error: %s", err.Error())
+			}
+		}
+
+		txData, err := tm.newTxData(ctx, to, value, data, accessList, gasPrice, nonce, gasLimit)
+		if err != nil {
+			if managed {
+				tm.nonceManager.Release(fromAddress, nonce)
+			}
+			return "", err
+		}
+		if simulation(ctx) {
+			if err := tm.simulate(ctx, fromAddress, types.NewTx(txData), abiStr); err != nil {
+				if managed {
+					tm.nonceManager.Release(fromAddress, nonce)
+				}
+				return "", err
+			}
+		}
+		signedTx, err := signer.SignTx(ctx, txSigner, types.NewTx(txData))
+		if err != nil {
+			if managed {
+				tm.nonceManager.Release(fromAddress, nonce)
+			}
+			return "", fmt.Errorf("sign tx error: %s", err.Error())
+		}
+
+		err = tm.broadcast(ctx, fromAddress, signedTx)
+		if !managed {
+			return signedTx.Hash().String(), err
+		}
+		if err == nil || !isRejected(err) {
+			// tx may have reached the node when there is no response, such as timeout, so its nonce is kept
+			tm.nonceManager.Commit(fromAddress, nonce)
+			return signedTx.Hash().String(), err
+		}
+		tm.nonceManager.Release(fromAddress, nonce)
+		// local nonce is behind the chain, resync and try once more
+		if resynced || !isNonceTooLow(err) {
+			return signedTx.Hash().String(), err
+		}
+		if err := tm.nonceManager.Resync(ctx, fromAddress); err != nil {
+			return "", err
+		}
+		resynced = true
 	}
-	price.SetUint64(gasPrice)
+}
 
+// newTxData makes tx data of the type sent by tm
+// pass gasPrice to nil and gasLimit to 0 to use default values, as SendTx does
+func (tm *TransactionManager) newTxData(ctx context.Context, to *common.Address, value *big.Int, data []byte, accessList types.AccessList, gasPrice *big.Int, nonce uint64, gasLimit uint64) (types.TxData, error) {
 	if gasLimit == 0 {
 		gasLimit = tm.gasLimit
 	}
 
-	// TODO go-ethereum: blockchain_test.go
-	// use NewTx()
-	var tx *types.Transaction
-	if toAddr != "" {
-		tx = types.NewTransaction(nonce, toAddress, value, gasLimit, price, data)
-	} else {
-		tx = types.NewContractCreation(nonce, value, gasLimit, price, data)
-	}
-	var signedTx *types.Transaction
-	if tm.eip155 {
+	var txData types.TxData
+	if tm.dynamicFee {
+		chainId, success := big.NewInt(0).SetString(tm.chainID, 10)
+		if !success {
+			return nil, ErrInvalidChainID
+		}
+		tip, feeCap, err := tm.dynamicFees(ctx, gasPrice)
+		if err != nil {
+			return nil, err
+		}
+		txData = &types.DynamicFeeTx{
+			ChainID:    chainId,
+			Nonce:      nonce,
+			GasTipCap:  tip,
+			GasFeeCap:  feeCap,
+			Gas:        gasLimit,
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
+		}
+	} else if accessList != nil {
 		chainId, success := big.NewInt(0).SetString(tm.chainID, 10)
 		if !success {
-			return "", fmt.Errorf("invalid chain id")
+			return nil, ErrInvalidChainID
+		}
+		price, err := tm.legacyGasPrice(ctx, gasPrice)
+		if err != nil {
+			return nil, err
+		}
+
+		txData = &types.AccessListTx{
+			ChainID:    chainId,
+			Nonce:      nonce,
+			GasPrice:   price,
+			Gas:        gasLimit,
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
 		}
-		signedTx, err = types.SignTx(tx, types.NewEIP155Signer(chainId), privK)
 	} else {
-		signedTx, err = types.SignTx(tx, types.HomesteadSigner{}, privK)
+		price, err := tm.legacyGasPrice(ctx, gasPrice)
+		if err != nil {
+			return nil, err
+		}
+
+		txData = &types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: price,
+			Gas:      gasLimit,
+			To:       to,
+			Value:    value,
+			Data:     data,
+		}
 	}
-	return signedTx.Hash().String(), tm.Client.SendTransaction(context.Background(), signedTx)
+
+	return txData, nil
+}
+
+// SendTxSync sends an sync tx, return hash, gas used, error
+// error is *RevertError with hash and gas used when tx is reverted
+func (tm *TransactionManager) SendTxSync(signer Signer, toAddr string, value *big.Int, data []byte, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, uint64, error) {
+	return tm.SendTxSyncContext(context.Background(), signer, toAddr, value, data, gasPrice, nonce, gasLimit)
 }
 
-// SendTxSync sends an sync tx
-// 调用者应该比较参数gasLimit和返回值的第二个gasUsed
-// 如果gasUsed 等于 gasLimit
-//    1. 如果这是个智能合约相关的操作(创建合约、写合约)，那么这个交易可能是部分完成，执行了部分指令, 用掉了gasLimit等量的gas，应该提高gasLimit上限重新调用一次
-//    2. 如果这是个转账操作，那么执行时成功的（转账的gasLimit为固定值21000） TODO 转账时gasLimit小于21000会发生啥
-func (tm *TransactionManager) SendTxSync(fromSK string, toAddr string, value *big.Int, data []byte, gasPrice uint64, nonce uint64, gasLimit uint64) (string, uint64, error) {
-	hash, err := tm.SendTx(fromSK, toAddr, value, data, gasPrice, nonce, gasLimit)
+// SendTxSyncContext is like SendTxSync but with ctx for cancellation and deadline
+func (tm *TransactionManager) SendTxSyncContext(ctx context.Context, signer Signer, toAddr string, value *big.Int, data []byte, gasPrice *big.Int, nonce uint64, gasLimit uint64) (string, uint64, error) {
+	hash, err := tm.SendTxContext(ctx, signer, toAddr, value, data, gasPrice, nonce, gasLimit)
 	if err != nil {
 		return "", 0, err
 	}
 
-	deadline := time.After(time.Second * time.Duration(tm.timeout))
-	tick := time.Tick(time.Second * time.Duration(tm.interval))
-	for {
-		select {
-		case <-deadline:
-			return "", 0, fmt.Errorf("timeout")
-		case <-tick:
-			receipt, err := tm.Client.TransactionReceipt(context.Background(), common.HexToHash(hash))
-			if err != nil {
-				// ignore
-			} else {
-				return hash, receipt.GasUsed, nil
-			}
-		}
+	receipt, err := tm.waitSuccess(ctx, hash, "")
+	if receipt == nil {
+		return "", 0, err
 	}
+	return hash, receipt.GasUsed, err
 }
 
 // TransferEth send an async eth-transfer tx
 // return tx hash,error
-func (tm *TransactionManager) TransferEth(fromSK string, toAddr string, value *big.Int, gasPrice uint64, nonce uint64) (string, error) {
-	return tm.SendTx(fromSK, toAddr, value, nil, gasPrice, nonce, transferEthLimit)
+func (tm *TransactionManager) TransferEth(signer Signer, toAddr string, value *big.Int, gasPrice *big.Int, nonce uint64) (string, error) {
+	return tm.TransferEthContext(context.Background(), signer, toAddr, value, gasPrice, nonce)
+}
+
+// TransferEthContext is like TransferEth but with ctx for cancellation and deadline
+func (tm *TransactionManager) TransferEthContext(ctx context.Context, signer Signer, toAddr string, value *big.Int, gasPrice *big.Int, nonce uint64) (string, error) {
+	return tm.SendTxContext(ctx, signer, toAddr, value, nil, gasPrice, nonce, tm.transferGasLimit(nil))
 }
 
 // TransferEthSync send an sync eth-transfer tx
 // return tx hash,error
-func (tm *TransactionManager) TransferEthSync(fromSK string, toAddr string, value *big.Int, gasPrice uint64, nonce uint64) (string, error) {
-	hash, err := tm.TransferEth(fromSK, toAddr, value, gasPrice, nonce)
+func (tm *TransactionManager) TransferEthSync(signer Signer, toAddr string, value *big.Int, gasPrice *big.Int, nonce uint64) (string, error) {
+	return tm.TransferEthSyncContext(context.Background(), signer, toAddr, value, gasPrice, nonce)
+}
+
+// TransferEthSyncContext is like TransferEthSync but with ctx for cancellation and deadline
+func (tm *TransactionManager) TransferEthSyncContext(ctx context.Context, signer Signer, toAddr string, value *big.Int, gasPrice *big.Int, nonce uint64) (string, error) {
+	hash, err := tm.TransferEthContext(ctx, signer, toAddr, value, gasPrice, nonce)
 	if err != nil {
 		return "", err
 	}
 
-	deadline := time.After(time.Second * time.Duration(tm.timeout))
-	tick := time.Tick(time.Second * time.Duration(tm.interval))
-	for {
-		select {
-		case <-deadline:
-			return "", fmt.Errorf("timeout")
-		case <-tick:
-			_, err := tm.Client.TransactionReceipt(context.Background(), common.HexToHash(hash))
-			if err != nil {
-				// skip
-			} else {
-				return hash, nil
-			}
-		}
+	if _, err := tm.waitSuccess(ctx, hash, ""); err != nil {
+		return hash, err
 	}
+	return hash, nil
 }
 
 // TransferEthWithData send an async eth-transfer tx
+// gas limit is estimated when gas estimation is enabled, otherwise it's tm.gasLimit when data is not empty
 // return tx hash,error
-func (tm *TransactionManager) TransferEthWithData(fromSK string, toAddr string, value *big.Int, data []byte, gasPrice uint64, nonce uint64) (string, error) {
-	return tm.SendTx(fromSK, toAddr, value, data, gasPrice, nonce, transferEthLimit)
+func (tm *TransactionManager) TransferEthWithData(signer Signer, toAddr string, value *big.Int, data []byte, gasPrice *big.Int, nonce uint64) (string, error) {
+	return tm.TransferEthWithDataContext(context.Background(), signer, toAddr, value, data, gasPrice, nonce)
+}
+
+// TransferEthWithDataContext is like TransferEthWithData but with ctx for cancellation and deadline
+func (tm *TransactionManager) TransferEthWithDataContext(ctx context.Context, signer Signer, toAddr string, value *big.Int, data []byte, gasPrice *big.Int, nonce uint64) (string, error) {
+	return tm.SendTxContext(ctx, signer, toAddr, value, data, gasPrice, nonce, tm.transferGasLimit(data))
 }
 
 // TransferEthWithDataSync send an sync eth-transfer tx
 // return tx hash,error
-func (tm *TransactionManager) TransferEthWithDataSync(fromSK string, toAddr string, value *big.Int, data []byte, gasPrice uint64, nonce uint64) (string, error) {
-	hash, err := tm.TransferEthWithData(fromSK, toAddr, value, data, gasPrice, nonce)
+func (tm *TransactionManager) TransferEthWithDataSync(signer Signer, toAddr string, value *big.Int, data []byte, gasPrice *big.Int, nonce uint64) (string, error) {
+	return tm.TransferEthWithDataSyncContext(context.Background(), signer, toAddr, value, data, gasPrice, nonce)
+}
+
+// TransferEthWithDataSyncContext is like TransferEthWithDataSync but with ctx for cancellation and deadline
+func (tm *TransactionManager) TransferEthWithDataSyncContext(ctx context.Context, signer Signer, toAddr string, value *big.Int, data []byte, gasPrice *big.Int, nonce uint64) (string, error) {
+	hash, err := tm.TransferEthWithDataContext(ctx, signer, toAddr, value, data, gasPrice, nonce)
 	if err != nil {
 		return "", err
 	}
 
-	deadline := time.After(time.Second * time.Duration(tm.timeout))
-	tick := time.Tick(time.Second * time.Duration(tm.interval))
-	for {
-		select {
-		case <-deadline:
-			return "", fmt.Errorf("timeout")
-		case <-tick:
-			_, err := tm.Client.TransactionReceipt(context.Background(), common.HexToHash(hash))
-			if err != nil {
-				// skip
-			} else {
-				return hash, nil
-			}
-		}
+	if _, err := tm.waitSuccess(ctx, hash, ""); err != nil {
+		return hash, err
 	}
+	return hash, nil
 }
 
 // GetBalance query balance of 'address'
 func (tm *TransactionManager) GetBalance(address string) (*big.Int, error) {
-	return tm.Client.BalanceAt(context.Background(), common.HexToAddress(address), nil)
+	return tm.GetBalanceContext(context.Background(), address)
+}
+
+// GetBalanceContext is like GetBalance but with ctx for cancellation and deadline
+func (tm *TransactionManager) GetBalanceContext(ctx context.Context, address string) (*big.Int, error) {
+	var balance *big.Int
+	err := tm.retry.Do(ctx, func() (err error) {
+		balance, err = tm.Client.BalanceAt(ctx, common.HexToAddress(address), nil)
+		return
+	})
+	return balance, err
 }
 
 func (tm *TransactionManager) Receipt(hash string) (*types.Receipt, error) {
-	return tm.Client.TransactionReceipt(context.Background(), common.HexToHash(hash))
+	return tm.ReceiptContext(context.Background(), hash)
+}
+
+// ReceiptContext is like Receipt but with ctx for cancellation and deadline
+func (tm *TransactionManager) ReceiptContext(ctx context.Context, hash string) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	err := tm.retry.Do(ctx, func() (err error) {
+		receipt, err = tm.Client.TransactionReceipt(ctx, common.HexToHash(hash))
+		return
+	})
+	return receipt, err
 }
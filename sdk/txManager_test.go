@@ -7,6 +7,8 @@ import (
 	"io/ioutil"
 	"math/big"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 const (
@@ -34,7 +36,7 @@ func TestCreateAccount(t *testing.T) {
 	t.Logf("\nsk: %s\naddr: %s\npk: %s\n", hex.EncodeToString(sk), hex.EncodeToString(addr), hex.EncodeToString(pk))
 }
 func TestGetBalance2(t *testing.T) {
-	txMan, err := New(rpcURL2, 0, createContractLimit, 0, 0)
+	txMan, err := New(rpcURL2, nil, createContractLimit, 0, 0)
 	if err != nil {
 		t.Fatalf("New txManager error: %s", err)
 	}
@@ -52,7 +54,7 @@ type ByteCode struct {
 }
 
 func TestTransferEth(t *testing.T) {
-	txMan, err := New(rpcURL2, 0, 21000, 0, 0)
+	txMan, err := New(rpcURL2, nil, 21000, 0, 0)
 	if err != nil {
 		t.Fatalf("new txman error: %v", err)
 	}
@@ -66,9 +68,9 @@ func TestTransferEth(t *testing.T) {
 		t.Fatalf("set v1 error: %v", err)
 	}
 
-	gasPrice := uint64(10)
+	gasPrice := big.NewInt(10)
 
-	txHash, err := txMan.TransferEth(fromSk, toAddr, value, gasPrice, 0)
+	txHash, err := txMan.TransferEth(hexSigner(t, fromSk), toAddr, value, gasPrice, 0)
 	if err != nil {
 		t.Fatalf("transfer eth error: %v", err)
 	}
@@ -76,7 +78,7 @@ func TestTransferEth(t *testing.T) {
 }
 
 func TestCreateContract(t *testing.T) {
-	txMan, err := New(rpcURL2, 0, createContractLimit, 0, 0)
+	txMan, err := New(rpcURL2, nil, createContractLimit, 0, 0)
 	if err != nil {
 		t.Fatalf("New txManager error: %s", err)
 	}
@@ -97,7 +99,7 @@ func TestCreateContract(t *testing.T) {
 		t.Fatalf("decode bytecode error: %v", err)
 	}
 
-	address, hash, gasUsed, err := txMan.CreateContractSync(sk1, bytecode, 0, 0, 0)
+	address, hash, gasUsed, err := txMan.CreateContractSync(hexSigner(t, sk1), bytecode, nil, 0, 0)
 	if err != nil {
 		t.Fatalf("create contract error: %s", err)
 	}
@@ -105,7 +107,7 @@ func TestCreateContract(t *testing.T) {
 
 }
 func TestWriteContract(t *testing.T) {
-	txMan, err := New(rpcURL2, 0, writeContractLimit, 0, 0)
+	txMan, err := New(rpcURL2, nil, writeContractLimit, 0, 0)
 	if err != nil {
 		t.Fatalf("New txManager error: %s", err)
 	}
@@ -118,7 +120,7 @@ func TestWriteContract(t *testing.T) {
 	abiStr := string(abiContent)
 	args := fmt.Sprintf("address:%v;uint256:1;", addr2)
 	t.Logf("args: %v", args)
-	hash, gasUsed, err := txMan.WriteContractSync(sk1, contractAddress, nil, abiStr, "transfer", args, 0, 0, 0)
+	hash, gasUsed, err := txMan.WriteContractSync(hexSigner(t, sk1), contractAddress, nil, abiStr, "transfer", args, nil, 0, 0)
 	if err != nil {
 		t.Fatalf("write contract error: %s", err.Error())
 	}
@@ -126,7 +128,7 @@ func TestWriteContract(t *testing.T) {
 }
 
 func TestReadContract(t *testing.T) {
-	txMan, err := New(rpcURL2, 0, readContractLimit, 0, 0)
+	txMan, err := New(rpcURL2, nil, readContractLimit, 0, 0)
 	if err != nil {
 		t.Fatalf("New txManager error: %s", err)
 	}
@@ -151,7 +153,7 @@ func TestGetCollectionByID(t *testing.T) {
 	rpc := "https://mainnet.infura.io/v3/f26e9265123241a4ba22cb9188089fe5"
 	abi := `[{"constant":true,"inputs":[],"name":"currentStartingDigitalMediaId","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"_interfaceID","type":"bytes4"}],"name":"supportsInterface","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_metadataPath","type":"string"}],"name":"createCollection","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_owner","type":"address"},{"name":"_totalSupply","type":"uint32"},{"name":"_digitalMediaMetadataPath","type":"string"},{"name":"_collectionMetadataPath","type":"string"},{"name":"_numReleases","type":"uint32"}],"name":"oboCreateDigitalMediaAndReleasesInNewCollection","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"_tokenId","type":"uint256"}],"name":"getApproved","outputs":[{"name":"","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_to","type":"address"},{"name":"_tokenId","type":"uint256"}],"name":"approve","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[],"name":"singleCreatorAddress","outputs":[{"name":"","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"currentDigitalMediaStore","outputs":[{"name":"","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_digitalMediaId","type":"uint256"}],"name":"burnDigitalMedia","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_from","type":"address"},{"name":"_to","type":"address"},{"name":"_tokenId","type":"uint256"}],"name":"transferFrom","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"_owner","type":"address"},{"name":"_index","type":"uint256"}],"name":"tokenOfOwnerByIndex","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"","type":"uint256"}],"name":"tokenIdToDigitalMediaRelease","outputs":[{"name":"printEdition","type":"uint32"},{"name":"digitalMediaId","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[],"name":"unpause","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_from","type":"address"},{"name":"_to","type":"address"},{"name":"_tokenId","type":"uint256"}],"name":"safeTransferFrom","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"tokenId","type":"uint256"}],"name":"burn","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_creatorAddress","type":"address"}],"name":"removeApprovedTokenCreator","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"_tokenId","type":"uint256"}],"name":"exists","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"_index","type":"uint256"}],"name":"tokenByIndex","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"","type":"address"}],"name":"approvedCreators","outputs":[{"name":"","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"_id","type":"uint256"}],"name":"getDigitalMedia","outputs":[{"name":"id","type":"uint256"},{"name":"totalSupply","type":"uint32"},{"name":"printIndex","type":"uint32"},{"name":"collectionId","type":"uint256"},{"name":"creator","type":"address"},{"name":"metadataPath","type":"string"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"_id","type":"uint256"}],"name":"getCollection","outputs":[{"name":"id","type":"uint256"},{"name":"creator","type":"address"},{"name":"metadataPath","type":"string"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"paused","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_creator","type":"address"},{"name":"_newCreator","type":"address"}],"name":"changeCreator","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_totalSupply","type":"uint32"},{"name":"_collectionId","type":"uint256"},{"name":"_metadataPath","type":"string"}],"name":"createDigitalMedia","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"_tokenId","type":"uint256"}],"name":"ownerOf","outputs":[{"name":"","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_owner","type":"address"},{"name":"_totalSupply","type":"uint32"},{"name":"_collectionId","type":"uint256"},{"name":"_metadataPath","type":"string"},{"name":"_numReleases","type":"uint32"}],"name":"oboCreateDigitalMediaAndReleases","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_to","type":"address"},{"name":"_approved","type":"bool"}],"name":"setOboApprovalForAll","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_tokenId","type":"uint256"}],"name":"burnToken","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_digitalMediaId","type":"uint256"},{"name":"_numReleases","type":"uint32"}],"name":"createDigitalMediaReleases","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[],"name":"pause","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"_id","type":"uint256"}],"name":"getDigitalMediaRelease","outputs":[{"name":"id","type":"uint256"},{"name":"printEdition","type":"uint32"},{"name":"digitalMediaId","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_totalSupply","type":"uint32"},{"name":"_digitalMediaMetadataPath","type":"string"},{"name":"_collectionMetadataPath","type":"string"},{"name":"_numReleases","type":"uint32"}],"name":"createDigitalMediaAndReleasesInNewCollection","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[],"name":"owner","outputs":[{"name":"","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_dmsAddress","type":"address"}],"name":"setV1DigitalMediaStoreAddress","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_oboAddress","type":"address"}],"name":"disableOboAddress","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"v1DigitalMediaStore","outputs":[{"name":"","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_to","type":"address"},{"name":"_approved","type":"bool"}],"name":"setApprovalForAll","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_newCreatorAddress","type":"address"}],"name":"changeSingleCreator","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_owner","type":"address"},{"name":"_digitalMediaId","type":"uint256"},{"name":"_numReleases","type":"uint32"}],"name":"oboCreateDigitalMediaReleases","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[],"name":"creatorRegistryStore","outputs":[{"name":"","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_from","type":"address"},{"name":"_to","type":"address"},{"name":"_tokenId","type":"uint256"},{"name":"_data","type":"bytes"}],"name":"safeTransferFrom","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"_tokenId","type":"uint256"}],"name":"tokenURI","outputs":[{"name":"","type":"string"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_tokenId","type":"uint256"}],"name":"resetApproval","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"","type":"address"}],"name":"approvedTokenCreators","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"","type":"address"}],"name":"disabledOboOperators","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"_owner","type":"address"},{"name":"_operator","type":"address"}],"name":"isApprovedForAll","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_totalSupply","type":"uint32"},{"name":"_collectionId","type":"uint256"},{"name":"_metadataPath","type":"string"},{"name":"_numReleases","type":"uint32"}],"name":"createDigitalMediaAndReleases","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"newOwner","type":"address"}],"name":"transferOwnership","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"_creatorAddress","type":"address"}],"name":"addApprovedTokenCreator","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"inputs":[{"name":"_tokenName","type":"string"},{"name":"_tokenSymbol","type":"string"},{"name":"_tokenIdStartingCounter","type":"uint256"},{"name":"_dmsAddress","type":"address"},{"name":"_crsAddress","type":"address"}],"payable":false,"stateMutability":"nonpayable","type":"constructor"},{"anonymous":false,"inputs":[{"indexed":false,"name":"_owner","type":"address"},{"indexed":false,"name":"_operator","type":"address"},{"indexed":false,"name":"_approved","type":"bool"}],"name":"OboApprovalForAll","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"name":"_operator","type":"address"}],"name":"OboDisabledForAll","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"name":"id","type":"uint256"},{"indexed":false,"name":"owner","type":"address"},{"indexed":false,"name":"printEdition","type":"uint32"},{"indexed":false,"name":"tokenURI","type":"string"},{"indexed":false,"name":"digitalMediaId","type":"uint256"}],"name":"DigitalMediaReleaseCreateEvent","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"name":"id","type":"uint256"},{"indexed":false,"name":"storeContractAddress","type":"address"},{"indexed":false,"name":"creator","type":"address"},{"indexed":false,"name":"totalSupply","type":"uint32"},{"indexed":false,"name":"printIndex","type":"uint32"},{"indexed":false,"name":"collectionId","type":"uint256"},{"indexed":false,"name":"metadataPath","type":"string"}],"name":"DigitalMediaCreateEvent","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"name":"id","type":"uint256"},{"indexed":false,"name":"storeContractAddress","type":"address"},{"indexed":false,"name":"creator","type":"address"},{"indexed":false,"name":"metadataPath","type":"string"}],"name":"DigitalMediaCollectionCreateEvent","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"name":"id","type":"uint256"},{"indexed":false,"name":"caller","type":"address"},{"indexed":false,"name":"storeContractAddress","type":"address"}],"name":"DigitalMediaBurnEvent","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"name":"tokenId","type":"uint256"},{"indexed":false,"name":"owner","type":"address"}],"name":"DigitalMediaReleaseBurnEvent","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"name":"digitalMediaId","type":"uint256"},{"indexed":false,"name":"printEdition","type":"uint32"}],"name":"UpdateDigitalMediaPrintIndexEvent","type":"event"},{"anonymous":false,"inputs":[{"indexed":false,"name":"creator","type":"address"},{"indexed":false,"name":"newCreator","type":"address"}],"name":"ChangedCreator","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"name":"previousCreatorAddress","type":"address"},{"indexed":true,"name":"newCreatorAddress","type":"address"}],"name":"SingleCreatorChanged","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"name":"_from","type":"address"},{"indexed":true,"name":"_to","type":"address"},{"indexed":false,"name":"_tokenId","type":"uint256"}],"name":"Transfer20","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"name":"_owner","type":"address"},{"indexed":true,"name":"_approved","type":"address"},{"indexed":false,"name":"_tokenId","type":"uint256"}],"name":"Approval","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"name":"_owner","type":"address"},{"indexed":true,"name":"_operator","type":"address"},{"indexed":false,"name":"_approved","type":"bool"}],"name":"ApprovalForAll","type":"event"},{"anonymous":false,"inputs":[],"name":"Pause","type":"event"},{"anonymous":false,"inputs":[],"name":"Unpause","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"name":"previousOwner","type":"address"},{"indexed":true,"name":"newOwner","type":"address"}],"name":"OwnershipTransferred","type":"event"}]`
 
-	man, err := New(rpc, 0, 21000, 0, 0)
+	man, err := New(rpc, nil, 21000, 0, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -165,3 +167,74 @@ func TestGetCollectionByID(t *testing.T) {
 	}
 	t.Logf("ret: %x", ret)
 }
+
+func hexSigner(t *testing.T, sk string) Signer {
+	signer, err := HexToSigner(sk)
+	if err != nil {
+		t.Fatalf("hex to signer error: %v", err)
+	}
+	return signer
+}
+
+func TestDynamicFeeTx(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.close()
+	backend.set(func(b *testBackend) {
+		b.baseFee = big.NewInt(10e9)
+		b.tip = big.NewInt(2e9)
+	})
+	tm, closeFn := backend.newTM(t)
+	defer closeFn()
+	tm.SetGasPriceStrategy(NewNodeGasPrice(tm.Client, 0))
+	tm.EnableDynamicFee()
+	signer := hexSigner(t, testSignerKey)
+
+	cases := []struct {
+		name       string
+		tip        *big.Int
+		gasPrice   *big.Int
+		wantTip    int64
+		wantFeeCap int64
+	}{
+		{"fees of node", nil, nil, 2e9, 22e9},
+		{"fee cap", nil, big.NewInt(15e9), 2e9, 15e9},
+		{"tip clamped to fee cap", nil, big.NewInt(1e9), 1e9, 1e9},
+		{"tip", big.NewInt(3e9), nil, 3e9, 23e9},
+	}
+	for _, c := range cases {
+		tm.SetGasTipCap(c.tip)
+		if _, err := tm.SendTx(signer, testAddress(1), big.NewInt(1), nil, c.gasPrice, 1, 21000); err != nil {
+			t.Fatalf("%v: %v", c.name, err)
+		}
+		tx := backend.lastSent(t)
+		if tx.Type() != types.DynamicFeeTxType || tx.ChainId().Int64() != 5 {
+			t.Fatalf("%v: type %v chain id %v", c.name, tx.Type(), tx.ChainId())
+		}
+		if tx.GasTipCap().Int64() != c.wantTip || tx.GasFeeCap().Int64() != c.wantFeeCap {
+			t.Fatalf("%v: tip %v fee cap %v", c.name, tx.GasTipCap(), tx.GasFeeCap())
+		}
+		if sender, err := types.Sender(types.NewLondonSigner(big.NewInt(5)), tx); err != nil || sender != signer.Address() {
+			t.Fatalf("%v: sender %v %v", c.name, sender.String(), err)
+		}
+	}
+
+	// fixed gas price is for legacy transactions, tip is suggested by node
+	tm.SetGasTipCap(nil)
+	tm.SetGasPriceStrategy(NewFixedGasPrice(big.NewInt(50e9), nil))
+	if _, err := tm.SendTx(signer, testAddress(1), big.NewInt(1), nil, nil, 1, 21000); err != nil {
+		t.Fatal(err)
+	}
+	if tx := backend.lastSent(t); tx.GasTipCap().Int64() != 2e9 || tx.GasFeeCap().Int64() != 22e9 {
+		t.Fatalf("fixed gas price: tip %v fee cap %v", tx.GasTipCap(), tx.GasFeeCap())
+	}
+
+	// chain before London has no base fee
+	backend.set(func(b *testBackend) { b.baseFee = nil })
+	if _, err := tm.SendTx(signer, testAddress(1), big.NewInt(1), nil, nil, 1, 21000); err == nil {
+		t.Fatal("expect error of missing base fee")
+	}
+	tm.DisableEIP155()
+	if _, err := tm.SendTx(signer, testAddress(1), big.NewInt(1), nil, big.NewInt(15e9), 1, 21000); err == nil {
+		t.Fatal("expect error of EIP-1559 without EIP155")
+	}
+}
@@ -0,0 +1,57 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestContextCancel(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.close()
+	tm, closeFn := backend.newTM(t)
+	defer closeFn()
+	signer := hexSigner(t, testSignerKey)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GetBalanceContext(canceled, backend.http.URL, testAddress(1)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect canceled, got %v", err)
+	}
+	if _, err := tm.GetBalanceContext(canceled, testAddress(1)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect canceled, got %v", err)
+	}
+	if _, err := tm.SendTxContext(canceled, signer, testAddress(1), big.NewInt(1), nil, nil, 0, 0); err == nil {
+		t.Fatal("expect error of canceled send")
+	}
+	if n := backend.count("eth_sendRawTransaction"); n != 0 {
+		t.Fatalf("%v transactions are sent", n)
+	}
+
+	// the same calls succeed with a live ctx
+	ctx := context.Background()
+	if balance, err := GetBalanceContext(ctx, backend.http.URL, testAddress(1)); err != nil || balance.Cmp(big.NewInt(1e18)) != 0 {
+		t.Fatalf("balance %v %v", balance, err)
+	}
+	hash, err := SendTxContext(ctx, backend.http.URL, signer, testAddress(1), 1, nil, big.NewInt(1e9), 21000)
+	if err != nil || backend.lastSent(t).Hash() != hash {
+		t.Fatalf("send: %v %v", hash.String(), err)
+	}
+	// it's protected with chain id of node
+	if sent := backend.lastSent(t); !sent.Protected() || sent.ChainId().Int64() != 5 {
+		t.Fatalf("tx of chain %v, protected %v", sent.ChainId(), sent.Protected())
+	}
+
+	// waiting of a tx never mined ends at deadline of ctx, not at tm.timeout
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := tm.TransferEthSyncContext(ctx, signer, testAddress(1), big.NewInt(1), nil, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("waited %v", elapsed)
+	}
+}
@@ -0,0 +1,425 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	eip712DomainType = "EIP712Domain"
+)
+
+var (
+	// fields of EIP712Domain in the order of EIP-712, used when types has no EIP712Domain
+	eip712DomainFields = []TypedDataField{
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+		{Name: "salt", Type: "bytes32"},
+	}
+)
+
+// TypedDataField is a member of a struct type of EIP-712
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData is an EIP-712 typed structured data document, the same as the parameter of eth_signTypedData_v4
+// values of Domain and Message can be decoded from JSON or Go values:
+// struct as map[string]interface{}, array as slice, address as string or common.Address,
+// bytes as hex string or []byte, integer as *big.Int, Go integer, decimal or hex string
+type TypedData struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]interface{}      `json:"domain"`
+	Message     map[string]interface{}      `json:"message"`
+}
+
+// ParseTypedData parses JSON typed data document
+func ParseTypedData(data []byte) (*TypedData, error) {
+	var td TypedData
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// keep big integers precise
+	decoder.UseNumber()
+	if err := decoder.Decode(&td); err != nil {
+		return nil, err
+	}
+	return &td, nil
+}
+
+// fields returns fields of struct type typ
+func (td *TypedData) fields(typ string) ([]TypedDataField, bool) {
+	fields, ok := td.Types[typ]
+	if !ok && typ == eip712DomainType {
+		// derive EIP712Domain from fields present in domain
+		for _, field := range eip712DomainFields {
+			if _, present := td.Domain[field.Name]; present {
+				fields = append(fields, field)
+			}
+		}
+		return fields, true
+	}
+	return fields, ok
+}
+
+// baseType strips array suffixes from typ, e.g. Person[][2] -> Person
+func baseType(typ string) string {
+	if i := strings.Index(typ, "["); i >= 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+// dependencies collects struct types referenced by typ recursively, including itself
+func (td *TypedData) dependencies(typ string, found map[string]bool) {
+	typ = baseType(typ)
+	if found[typ] {
+		return
+	}
+	fields, ok := td.fields(typ)
+	if !ok {
+		// primitive type
+		return
+	}
+	found[typ] = true
+	for _, field := range fields {
+		td.dependencies(field.Type, found)
+	}
+}
+
+// EncodeType returns encodeType of EIP-712, e.g. Mail(Person from,Person to,string contents)Person(string name,address wallet)
+func (td *TypedData) EncodeType(primaryType string) (string, error) {
+	if _, ok := td.fields(primaryType); !ok {
+		return "", fmt.Errorf("type %v is undefined", primaryType)
+	}
+	found := make(map[string]bool)
+	td.dependencies(primaryType, found)
+	delete(found, primaryType)
+	deps := make([]string, 0, len(found))
+	for dep := range found {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+
+	var buf strings.Builder
+	for _, typ := range append([]string{primaryType}, deps...) {
+		fields, _ := td.fields(typ)
+		members := make([]string, len(fields))
+		for i, field := range fields {
+			members[i] = field.Type + " " + field.Name
+		}
+		fmt.Fprintf(&buf, "%v(%v)", typ, strings.Join(members, ","))
+	}
+	return buf.String(), nil
+}
+
+// TypeHash returns keccak256 of EncodeType of primaryType
+func (td *TypedData) TypeHash(primaryType string) (common.Hash, error) {
+	encoded, err := td.EncodeType(primaryType)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash([]byte(encoded)), nil
+}
+
+// HashStruct returns hashStruct of EIP-712: keccak256(typeHash ‖ encodeData(data))
+func (td *TypedData) HashStruct(primaryType string, data map[string]interface{}) (common.Hash, error) {
+	typeHash, err := td.TypeHash(primaryType)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	fields, _ := td.fields(primaryType)
+	if len(data) > len(fields) {
+		return common.Hash{}, fmt.Errorf("%v has %v fields, but %v values are provided", primaryType, len(fields), len(data))
+	}
+
+	buf := bytes.NewBuffer(typeHash.Bytes())
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if !ok {
+			return common.Hash{}, fmt.Errorf("value of %v.%v is missing", primaryType, field.Name)
+		}
+		encoded, err := td.encodeValue(field.Type, value)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("encode %v.%v error: %s", primaryType, field.Name, err.Error())
+		}
+		buf.Write(encoded)
+	}
+	return crypto.Keccak256Hash(buf.Bytes()), nil
+}
+
+// DomainSeparator returns hashStruct of domain
+func (td *TypedData) DomainSeparator() (common.Hash, error) {
+	return td.HashStruct(eip712DomainType, td.Domain)
+}
+
+// Hash returns the digest to sign: keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message))
+func (td *TypedData) Hash() (common.Hash, error) {
+	domainSeparator, err := td.DomainSeparator()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	messageHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator.Bytes(), messageHash.Bytes()), nil
+}
+
+// encodeValue encodes value of typ as a 32 bytes word
+func (td *TypedData) encodeValue(typ string, value interface{}) ([]byte, error) {
+	// array: keccak256 of concatenated encoding of elements
+	if strings.HasSuffix(typ, "]") {
+		i := strings.LastIndex(typ, "[")
+		elemType, size := typ[:i], typ[i+1:len(typ)-1]
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, fmt.Errorf("%v is not an array of %v", value, typ)
+		}
+		if size != "" {
+			n, err := strconv.Atoi(size)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array type %v", typ)
+			}
+			if rv.Len() != n {
+				return nil, fmt.Errorf("array %v has %v elements", typ, rv.Len())
+			}
+		}
+		var buf bytes.Buffer
+		for i := 0; i < rv.Len(); i++ {
+			encoded, err := td.encodeValue(elemType, rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(encoded)
+		}
+		return crypto.Keccak256(buf.Bytes()), nil
+	}
+
+	// struct: hashStruct
+	if _, ok := td.fields(typ); ok {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v is not a struct of %v", value, typ)
+		}
+		hash, err := td.HashStruct(typ, data)
+		if err != nil {
+			return nil, err
+		}
+		return hash.Bytes(), nil
+	}
+
+	switch {
+	case typ == "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a string", value)
+		}
+		return crypto.Keccak256([]byte(s)), nil
+
+	case typ == "bytes":
+		b, err := typedBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(b), nil
+
+	case typ == "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a bool", value)
+		}
+		if b {
+			return math.PaddedBigBytes(common.Big1, 32), nil
+		}
+		return make([]byte, 32), nil
+
+	case typ == "address":
+		var address common.Address
+		switch v := value.(type) {
+		case common.Address:
+			address = v
+		case string:
+			if !common.IsHexAddress(v) {
+				return nil, fmt.Errorf("%v is not an address", value)
+			}
+			address = common.HexToAddress(v)
+		default:
+			return nil, fmt.Errorf("%v is not an address", value)
+		}
+		return common.LeftPadBytes(address.Bytes(), 32), nil
+
+	case strings.HasPrefix(typ, "bytes"):
+		n, err := strconv.Atoi(strings.TrimPrefix(typ, "bytes"))
+		if err != nil || n < 1 || n > 32 {
+			return nil, fmt.Errorf("invalid type %v", typ)
+		}
+		b, err := typedBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != n {
+			return nil, fmt.Errorf("%v has %v bytes instead of %v", value, len(b), n)
+		}
+		return common.RightPadBytes(b, 32), nil
+
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		return typedInteger(typ, value)
+	}
+	return nil, fmt.Errorf("unknown type %v", typ)
+}
+
+// typedBytes converts hex string, []byte or byte array to bytes
+func typedBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case hexutil.Bytes:
+		return v, nil
+	case common.Hash:
+		return v.Bytes(), nil
+	case string:
+		b, err := hexutil.Decode(v)
+		if err != nil {
+			return nil, fmt.Errorf("%v is not hex bytes: %s", v, err.Error())
+		}
+		return b, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return b, nil
+	}
+	return nil, fmt.Errorf("%v is not bytes", value)
+}
+
+// typedInteger encodes value as intN or uintN in two's complement
+func typedInteger(typ string, value interface{}) ([]byte, error) {
+	signed := strings.HasPrefix(typ, "int")
+	bits := 256
+	if size := strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 8 || n > 256 || n%8 != 0 {
+			return nil, fmt.Errorf("invalid type %v", typ)
+		}
+		bits = n
+	}
+
+	var n *big.Int
+	switch v := value.(type) {
+	case *big.Int:
+		n = new(big.Int).Set(v)
+	case big.Int:
+		n = new(big.Int).Set(&v)
+	case *hexutil.Big:
+		n = new(big.Int).Set(v.ToInt())
+	case json.Number:
+		n, _ = new(big.Int).SetString(v.String(), 10)
+	case string:
+		if strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X") {
+			n, _ = new(big.Int).SetString(v[2:], 16)
+		} else {
+			n, _ = new(big.Int).SetString(v, 10)
+		}
+	case float64:
+		// JSON number decoded without UseNumber
+		if v == float64(int64(v)) {
+			n = big.NewInt(int64(v))
+		}
+	default:
+		rv := reflect.ValueOf(value)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = big.NewInt(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = new(big.Int).SetUint64(rv.Uint())
+		}
+	}
+	if n == nil {
+		return nil, fmt.Errorf("%v is not an integer", value)
+	}
+
+	if signed {
+		limit := new(big.Int).Lsh(common.Big1, uint(bits-1))
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("%v overflows %v", n, typ)
+		}
+	} else if n.Sign() < 0 || n.BitLen() > bits {
+		return nil, fmt.Errorf("%v overflows %v", n, typ)
+	}
+	return math.U256Bytes(n), nil
+}
+
+// SignTypedData signs EIP-712 typed data as eth_signTypedData_v4 does, return 65 bytes signature with V 27 or 28
+func SignTypedData(signer MessageSigner, td *TypedData) ([]byte, error) {
+	return SignTypedDataContext(context.Background(), signer, td)
+}
+
+// SignTypedDataContext is like SignTypedData but with ctx for cancellation and deadline
+func SignTypedDataContext(ctx context.Context, signer MessageSigner, td *TypedData) ([]byte, error) {
+	return signer.SignTypedData(ctx, td)
+}
+
+func (s *KeySigner) SignTypedData(ctx context.Context, td *TypedData) ([]byte, error) {
+	hash, err := td.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return signHash(s.key, hash.Bytes())
+}
+
+func (s *UnlockedSigner) SignTypedData(ctx context.Context, td *TypedData) ([]byte, error) {
+	hash, err := td.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return s.signHash(hash.Bytes())
+}
+
+func (s *ExternalSigner) SignTypedData(ctx context.Context, td *TypedData) ([]byte, error) {
+	// hash first, so invalid typed data is never sent
+	hash, err := td.Hash()
+	if err != nil {
+		return nil, err
+	}
+	var sig hexutil.Bytes
+	// pointer makes MixedcaseAddress marshal as string
+	address := common.NewMixedcaseAddress(s.address)
+	if err := s.client.CallContext(ctx, &sig, "account_signTypedData", &address, td); err != nil {
+		return nil, fmt.Errorf("account_signTypedData error: %s", err.Error())
+	}
+	return s.checkSignature(hash.Bytes(), sig)
+}
+
+// RecoverTypedDataSigner recovers address of signer of typed data, V of sig can be either 27/28 or 0/1
+func RecoverTypedDataSigner(td *TypedData, sig []byte) (common.Address, error) {
+	hash, err := td.Hash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return recoverHashSigner(hash.Bytes(), sig)
+}
+
+// VerifyTypedData reports whether sig of typed data is signed by address
+func VerifyTypedData(address string, td *TypedData, sig []byte) (bool, error) {
+	signer, err := RecoverTypedDataSigner(td, sig)
+	if err != nil {
+		return false, err
+	}
+	return signer == common.HexToAddress(address), nil
+}
@@ -0,0 +1,272 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// example of EIP-712
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestTypedDataMail(t *testing.T) {
+	td, err := ParseTypedData([]byte(mailTypedData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := td.EncodeType("Mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != "Mail(Person from,Person to,string contents)Person(string name,address wallet)" {
+		t.Fatalf("encodeType: %v", encoded)
+	}
+
+	checks := []struct {
+		name string
+		hash func() (common.Hash, error)
+		want string
+	}{
+		{"typeHash", func() (common.Hash, error) { return td.TypeHash("Mail") }, "0xa0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"},
+		{"hashStruct", func() (common.Hash, error) { return td.HashStruct("Mail", td.Message) }, "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"},
+		{"domainSeparator", td.DomainSeparator, "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"},
+		{"digest", td.Hash, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"},
+	}
+	for _, c := range checks {
+		hash, err := c.hash()
+		if err != nil {
+			t.Fatalf("%v error: %v", c.name, err)
+		}
+		if hash.String() != c.want {
+			t.Fatalf("%v: %v want %v", c.name, hash.String(), c.want)
+		}
+	}
+
+	cowKey := hex.EncodeToString(crypto.Keccak256([]byte("cow")))
+	cow, err := HexToSigner(cowKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignTypedData(cow, td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" + "1c"
+	if hex.EncodeToString(sig) != want {
+		t.Fatalf("signature: %x", sig)
+	}
+	ok, err := VerifyTypedData("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826", td, sig)
+	if err != nil || !ok {
+		t.Fatalf("verify: %v %v", ok, err)
+	}
+}
+
+func TestTypedDataSigners(t *testing.T) {
+	td, err := ParseTypedData([]byte(mailTypedData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keySigner, err := HexToSigner(testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := SignTypedData(keySigner, td)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	account, err := ImportHexKeystoreAccount(dir, testSignerKey, "pass", LightScrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlocked, err := UnlockKeystore(account.File, "pass", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newStandInClient(t, &standInSigner{key: keySigner.key})
+	defer client.Close()
+	external := NewExternalSignerWithClient(client, keySigner.Address().String())
+
+	for _, signer := range []MessageSigner{unlocked, external} {
+		sig, err := SignTypedDataContext(context.Background(), signer, td)
+		if err != nil {
+			t.Fatalf("%v: %v", signer, err)
+		}
+		if !bytes.Equal(sig, want) {
+			t.Fatalf("%v: signature %x want %x", signer, sig, want)
+		}
+	}
+
+	unlocked.Lock()
+	if _, err := SignTypedData(unlocked, td); err == nil {
+		t.Fatal("expect error of locked account")
+	}
+	tampered := newStandInClient(t, &standInSigner{key: keySigner.key, tamper: true})
+	defer tampered.Close()
+	if _, err := SignTypedData(NewExternalSignerWithClient(tampered, keySigner.Address().String()), td); err == nil {
+		t.Fatal("expect error of signature of other data")
+	}
+}
+
+func TestTypedDataGoValues(t *testing.T) {
+	td := &TypedData{
+		Types: map[string][]TypedDataField{
+			"Person": {{Name: "name", Type: "string"}, {Name: "wallet", Type: "address"}},
+			"Mail":   {{Name: "from", Type: "Person"}, {Name: "to", Type: "Person"}, {Name: "contents", Type: "string"}},
+		},
+		PrimaryType: "Mail",
+		// EIP712Domain is derived from the domain
+		Domain: map[string]interface{}{
+			"name":              "Ether Mail",
+			"version":           "1",
+			"chainId":           big.NewInt(1),
+			"verifyingContract": common.HexToAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"),
+		},
+		Message: map[string]interface{}{
+			"from":     map[string]interface{}{"name": "Cow", "wallet": common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")},
+			"to":       map[string]interface{}{"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+			"contents": "Hello, Bob!",
+		},
+	}
+	hash, err := td.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash.String() != "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2" {
+		t.Fatalf("digest: %v", hash.String())
+	}
+}
+
+func TestTypedDataArrays(t *testing.T) {
+	td, err := ParseTypedData([]byte(`{
+		"types": {
+			"EIP712Domain": [{"name": "name", "type": "string"}],
+			"Person": [{"name": "name", "type": "string"}, {"name": "tags", "type": "bytes32[]"}],
+			"Group": [
+				{"name": "members", "type": "Person[]"},
+				{"name": "matrix", "type": "int8[2][]"},
+				{"name": "data", "type": "bytes"}
+			]
+		},
+		"primaryType": "Group",
+		"domain": {"name": "test"},
+		"message": {
+			"members": [
+				{"name": "a", "tags": ["0x0000000000000000000000000000000000000000000000000000000000000001"]},
+				{"name": "b", "tags": []}
+			],
+			"matrix": [[1, -1], [127, -128]],
+			"data": "0x1234"
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, _ := td.EncodeType("Group")
+	if encoded != "Group(Person[] members,int8[2][] matrix,bytes data)Person(string name,bytes32[] tags)" {
+		t.Fatalf("encodeType: %v", encoded)
+	}
+
+	// compute hashStruct of Group by hand
+	word := func(n int64) []byte { return common.LeftPadBytes(big.NewInt(n).Bytes(), 32) }
+	neg := func(n int64) []byte {
+		b, _ := typedInteger("int8", big.NewInt(n))
+		return b
+	}
+	personType := crypto.Keccak256([]byte("Person(string name,bytes32[] tags)"))
+	a := crypto.Keccak256(personType, crypto.Keccak256([]byte("a")), crypto.Keccak256(word(1)))
+	b := crypto.Keccak256(personType, crypto.Keccak256([]byte("b")), crypto.Keccak256())
+	members := crypto.Keccak256(a, b)
+	row0 := crypto.Keccak256(word(1), neg(-1))
+	row1 := crypto.Keccak256(word(127), neg(-128))
+	matrix := crypto.Keccak256(row0, row1)
+	groupType := crypto.Keccak256([]byte(encoded))
+	want := crypto.Keccak256(groupType, members, matrix, crypto.Keccak256([]byte{0x12, 0x34}))
+
+	got, err := td.HashStruct("Group", td.Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Fatalf("hashStruct: %x want %x", got, want)
+	}
+
+	signer, _ := HexToSigner(testSignerKey)
+	sig, err := signer.SignTypedData(context.Background(), td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := RecoverTypedDataSigner(td, sig)
+	if err != nil || recovered != signer.Address() {
+		t.Fatalf("recovered %v %v", recovered.String(), err)
+	}
+}
+
+func TestTypedDataInvalid(t *testing.T) {
+	cases := []struct {
+		typ   string
+		value interface{}
+	}{
+		{"uint8", 256},
+		{"uint256", -1},
+		{"int8", 128},
+		{"int8", -129},
+		{"bytes4", "0x1234"},
+		{"address", "0x1234"},
+		{"bool", "true"},
+		{"uint256[2]", []interface{}{1}},
+		{"Unknown", 1},
+	}
+	td := &TypedData{}
+	for _, c := range cases {
+		if _, err := td.encodeValue(c.typ, c.value); err == nil {
+			t.Fatalf("expect error of %v %v", c.typ, c.value)
+		}
+	}
+	if b, err := td.encodeValue("int8", -1); err != nil || !bytes.Equal(b, bytes.Repeat([]byte{0xff}, 32)) {
+		t.Fatalf("int8 -1: %x %v", b, err)
+	}
+}
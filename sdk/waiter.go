@@ -0,0 +1,523 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultConfirmations = 1
+	// blocks a tx is still tracked for reorg after its wait returned
+	defaultReorgDepth = 6
+	// events buffered for each subscriber, more events are dropped until it catches up
+	txEventBuffer = 256
+)
+
+// waiterBackend is the part of *ethclient.Client used by receiptWaiter
+type waiterBackend interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// receiptWaiter waits receipts of many transactions with one goroutine
+// the goroutine is driven by new heads subscription when backend supports it (websocket, ipc),
+// otherwise it polls block number every interval, and it exits when nothing is waited
+// receipts are fetched again at every head, so a tx reorganised away is not counted as confirmed
+type receiptWaiter struct {
+	backend  waiterBackend
+	interval time.Duration
+
+	mu      sync.Mutex
+	waits   map[*receiptWait]struct{}
+	running bool
+
+	tracked     map[common.Hash]*trackState // only used by loop goroutine
+	subsMu      sync.Mutex
+	subs        map[*txEventSub]struct{} // subscribers of TxEvent of waited transactions
+	rebroadcast func(ctx context.Context, hash common.Hash)
+	retry       *RetryPolicy // of receipt and block number requests, waits fail when receipt requests fail with it
+}
+
+// trackState is the block a waited tx is seen in
+type trackState struct {
+	block   common.Hash
+	dropped bool
+}
+
+type receiptWait struct {
+	hashes        []common.Hash // any of hashes, e.g. a tx and its replacements
+	confirmations uint64
+	silent        bool                // confirmation is not reported, the tx is already returned to caller
+	done          chan *types.Receipt // buffered, receives the confirmed receipt
+	failed        chan error          // buffered, receives error of receipt requests
+}
+
+func newReceiptWait(hashes []common.Hash, confirmations uint64) *receiptWait {
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	return &receiptWait{
+		hashes:        hashes,
+		confirmations: confirmations,
+		done:          make(chan *types.Receipt, 1),
+		failed:        make(chan error, 1),
+	}
+}
+
+// fail finishes rw with err
+func (rw *receiptWait) fail(err error) {
+	select {
+	case rw.failed <- err:
+	default:
+	}
+}
+
+func newReceiptWaiter(backend waiterBackend, interval time.Duration) *receiptWaiter {
+	return &receiptWaiter{
+		backend:  backend,
+		interval: interval,
+		waits:    make(map[*receiptWait]struct{}),
+		tracked:  make(map[common.Hash]*trackState),
+		subs:     make(map[*txEventSub]struct{}),
+	}
+}
+
+// txEventSub delivers events to ch from its own buffer,
+// so a slow subscriber never blocks the waiter loop, it loses events when the buffer is full
+type txEventSub struct {
+	w       *receiptWaiter
+	ch      chan<- TxEvent
+	buf     chan TxEvent
+	quit    chan struct{}
+	err     chan error
+	once    sync.Once
+	dropped int // events lost when buf is full, guarded by subsMu of w
+}
+
+func (s *txEventSub) forward() {
+	for {
+		select {
+		case e := <-s.buf:
+			select {
+			case s.ch <- e:
+			case <-s.quit:
+				return
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+func (s *txEventSub) Unsubscribe() {
+	s.once.Do(func() {
+		s.w.subsMu.Lock()
+		delete(s.w.subs, s)
+		s.w.subsMu.Unlock()
+		close(s.quit)
+		close(s.err)
+	})
+}
+
+func (s *txEventSub) Err() <-chan error {
+	return s.err
+}
+
+// subscribe sends TxEvent of waited transactions to ch
+func (w *receiptWaiter) subscribe(ch chan<- TxEvent) event.Subscription {
+	s := &txEventSub{
+		w:    w,
+		ch:   ch,
+		buf:  make(chan TxEvent, txEventBuffer),
+		quit: make(chan struct{}),
+		err:  make(chan error),
+	}
+	w.subsMu.Lock()
+	w.subs[s] = struct{}{}
+	w.subsMu.Unlock()
+	go s.forward()
+	return s
+}
+
+// send queues e to every subscriber without blocking
+func (w *receiptWaiter) send(e TxEvent) {
+	w.subsMu.Lock()
+	defer w.subsMu.Unlock()
+
+	for s := range w.subs {
+		select {
+		case s.buf <- e:
+		default:
+			// warn once, a slow subscriber would flood the log
+			if s.dropped == 0 {
+				logrus.Warnf("tx event subscriber is full, events are dropped until it catches up")
+			}
+			s.dropped++
+		}
+	}
+}
+
+// wait blocks until any of hashes is mined with confirmations blocks (including its own block) or ctx is done
+func (w *receiptWaiter) wait(ctx context.Context, hashes []common.Hash, confirmations uint64) (*types.Receipt, error) {
+	rw := newReceiptWait(hashes, confirmations)
+	w.add(rw)
+	defer w.remove(rw)
+
+	select {
+	case receipt := <-rw.done:
+		return receipt, nil
+	case err := <-rw.failed:
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// waitAll blocks until every hash is mined with confirmations blocks or ctx is done,
+// receipts are in the order of hashes, the ones not confirmed are nil
+func (w *receiptWaiter) waitAll(ctx context.Context, hashes []string, confirmations uint64) ([]*types.Receipt, error) {
+	waits := make([]*receiptWait, len(hashes))
+	for i, hash := range hashes {
+		waits[i] = newReceiptWait([]common.Hash{common.HexToHash(hash)}, confirmations)
+		w.add(waits[i])
+		defer w.remove(waits[i])
+	}
+
+	receipts := make([]*types.Receipt, len(hashes))
+	for i, rw := range waits {
+		var err error
+		select {
+		case receipts[i] = <-rw.done:
+			continue
+		case err = <-rw.failed:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		// collect the ones already confirmed
+		for j := i + 1; j < len(waits); j++ {
+			select {
+			case receipts[j] = <-waits[j].done:
+			default:
+			}
+		}
+		return receipts, err
+	}
+	return receipts, nil
+}
+
+// follow waits rw in background for at most timeout, nobody reads its result
+func (w *receiptWaiter) follow(rw *receiptWait, timeout time.Duration) {
+	w.add(rw)
+	time.AfterFunc(timeout, func() {
+		w.remove(rw)
+	})
+}
+
+func (w *receiptWaiter) add(rw *receiptWait) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.waits[rw] = struct{}{}
+	if !w.running {
+		w.running = true
+		go w.loop()
+	}
+}
+
+func (w *receiptWaiter) remove(rw *receiptWait) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.waits, rw)
+}
+
+// pending returns waits not done yet, it marks loop stopped when there is none
+func (w *receiptWaiter) pending() []*receiptWait {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.waits) == 0 {
+		w.running = false
+		return nil
+	}
+	ret := make([]*receiptWait, 0, len(w.waits))
+	for rw := range w.waits {
+		ret = append(ret, rw)
+	}
+	return ret
+}
+
+func (w *receiptWaiter) loop() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		heads   = make(chan *types.Header, 16)
+		subErr  <-chan error
+		current *big.Int
+		sub     ethereum.Subscription
+	)
+	// sub is only set on success, ethclient returns a typed nil subscription with error
+	if s, err := w.backend.SubscribeNewHead(ctx, heads); err == nil {
+		sub = s
+		defer sub.Unsubscribe()
+		subErr = sub.Err()
+	}
+	// ticker polls block number when subscription is not available,
+	// beside subscription it only sees whether loop should exit
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case head := <-heads:
+			current = head.Number
+		case err := <-subErr:
+			logrus.Warnf("new head subscription error: %v, fall back to polling", err)
+			subErr = nil
+			sub = nil
+			continue
+		case <-ticker.C:
+			if sub != nil && current != nil {
+				// subscription drives checks, just see whether to exit
+				if w.pending() == nil {
+					return
+				}
+				continue
+			}
+			var number uint64
+			err := w.retry.Do(ctx, func() (err error) {
+				number, err = w.backend.BlockNumber(ctx)
+				return
+			})
+			if err != nil {
+				// waits are ended by their own deadline, not by one failure of node
+				logrus.Warnf("BlockNumber() error: %v", err)
+				if w.pending() == nil {
+					return
+				}
+				continue
+			}
+			current = new(big.Int).SetUint64(number)
+		}
+
+		waits := w.pending()
+		if waits == nil {
+			return
+		}
+		w.check(ctx, current, waits)
+	}
+}
+
+// check fetches receipt of every distinct hash once, reports changes of them,
+// and finishes waits confirmed at head
+func (w *receiptWaiter) check(ctx context.Context, head *big.Int, waits []*receiptWait) {
+	receipts := make(map[common.Hash]*types.Receipt)
+	failed := make(map[common.Hash]error)
+	for _, rw := range waits {
+		for _, hash := range rw.hashes {
+			if _, fetched := receipts[hash]; fetched || failed[hash] != nil {
+				continue
+			}
+			var receipt *types.Receipt
+			err := w.retry.Do(ctx, func() (err error) {
+				receipt, err = w.backend.TransactionReceipt(ctx, hash)
+				return
+			})
+			if err == ethereum.NotFound {
+				receipt = nil
+			} else if err != nil {
+				// terminal, or transient one with retries used up
+				logrus.Warnf("TransactionReceipt() of %v error: %v", hash.String(), err)
+				failed[hash] = fmt.Errorf("TransactionReceipt() of %v error: %s", hash.String(), err.Error())
+				continue
+			}
+			receipts[hash] = receipt
+			w.track(ctx, hash, receipt)
+		}
+	}
+
+	waited := make(map[common.Hash]bool)
+	for _, rw := range waits {
+		confirmed := false
+		var (
+			err      error
+			failures int
+		)
+		for _, hash := range rw.hashes {
+			if failed[hash] != nil {
+				err = failed[hash]
+				failures++
+			}
+			waited[hash] = true
+			receipt := receipts[hash]
+			if confirmed || receipt == nil || receipt.BlockNumber == nil || head.Cmp(receipt.BlockNumber) < 0 {
+				continue
+			}
+			depth := new(big.Int).Sub(head, receipt.BlockNumber).Uint64() + 1
+			if depth >= rw.confirmations {
+				confirmed = true
+				select {
+				case rw.done <- receipt:
+				default:
+				}
+				w.remove(rw)
+				if !rw.silent {
+					w.send(TxEvent{Type: TxConfirmed, Hash: hash, Receipt: receipt})
+				}
+			}
+		}
+		// a wait of a tx and its replacements fails only when receipts of all of them failed
+		if !confirmed && failures == len(rw.hashes) {
+			w.remove(rw)
+			rw.fail(err)
+		}
+	}
+
+	// forget hashes nobody waits for
+	for hash := range w.tracked {
+		if !waited[hash] {
+			delete(w.tracked, hash)
+		}
+	}
+}
+
+// track compares receipt of hash with the one seen before,
+// it reports mined, dropped and re-included tx, and re-broadcasts dropped tx
+// receipt is nil when tx is not in canonical chain
+func (w *receiptWaiter) track(ctx context.Context, hash common.Hash, receipt *types.Receipt) {
+	state, seen := w.tracked[hash]
+	switch {
+	case receipt == nil:
+		if seen && !state.dropped {
+			state.dropped = true
+			logrus.Warnf("transaction %v is dropped from block %v by reorg", hash.String(), state.block.String())
+			w.send(TxEvent{Type: TxDropped, Hash: hash})
+			if w.rebroadcast != nil {
+				w.rebroadcast(ctx, hash)
+			}
+		}
+	case !seen:
+		w.tracked[hash] = &trackState{block: receipt.BlockHash}
+		w.send(TxEvent{Type: TxMined, Hash: hash, Receipt: receipt})
+	case state.dropped || state.block != receipt.BlockHash:
+		logrus.Infof("transaction %v is re-included in block %v", hash.String(), receipt.BlockHash.String())
+		state.block = receipt.BlockHash
+		state.dropped = false
+		w.send(TxEvent{Type: TxReincluded, Hash: hash, Receipt: receipt})
+	}
+}
+
+// SetConfirmations sets how many blocks (including the block of tx) the *Sync helpers wait for,
+// default is 1, which returns as soon as the receipt is available
+// a tx returned with fewer blocks than the reorg depth is still tracked in background, see SetReorgDepth
+func (tm *TransactionManager) SetConfirmations(confirmations uint64) {
+	if confirmations == 0 {
+		confirmations = defaultConfirmations
+	}
+	tm.confirmations = confirmations
+}
+
+// SetReorgDepth sets how many blocks a tx is tracked for after a wait of it returned, default is 6
+// until then (or tm.timeout seconds) it's reported to SubscribeTxEvents and re-broadcast when reorganised away,
+// it doesn't delay the *Sync helpers, set 0 to stop tracking when the wait returns
+func (tm *TransactionManager) SetReorgDepth(depth uint64) {
+	tm.reorgDepth = depth
+}
+
+// followReorg keeps tracking hash mined with confirmations blocks to tm.reorgDepth in background
+func (tm *TransactionManager) followReorg(hash common.Hash, confirmations uint64) {
+	if tm.reorgDepth <= confirmations {
+		return
+	}
+	rw := newReceiptWait([]common.Hash{hash}, tm.reorgDepth)
+	rw.silent = true
+	tm.waiter.follow(rw, time.Second*time.Duration(tm.timeout))
+}
+
+// timeoutErr returns ErrTimeout for err of a wait ended by tm.timeout,
+// the caller's own deadline and cancellation of ctx are returned as they are
+func timeoutErr(ctx context.Context, err error) error {
+	if err == context.DeadlineExceeded && ctx.Err() == nil {
+		return ErrTimeout
+	}
+	return err
+}
+
+// waitReceipt waits receipt of hash with tm.confirmations,
+// until tm.timeout seconds passed or ctx is done
+func (tm *TransactionManager) waitReceipt(ctx context.Context, hash string) (*types.Receipt, error) {
+	return tm.WaitReceiptContext(ctx, hash, tm.confirmations)
+}
+
+// WaitReceipt waits receipt of hash mined with confirmations blocks (including its own block),
+// it returns ErrTimeout after tm.timeout seconds
+// the Context variants return ctx.Err() when ctx is done before that
+func (tm *TransactionManager) WaitReceipt(hash string, confirmations uint64) (*types.Receipt, error) {
+	return tm.WaitReceiptContext(context.Background(), hash, confirmations)
+}
+
+// WaitReceiptContext is like WaitReceipt but with ctx for cancellation and deadline
+func (tm *TransactionManager) WaitReceiptContext(ctx context.Context, hash string, confirmations uint64) (*types.Receipt, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(tm.timeout))
+	defer cancel()
+
+	receipt, err := tm.waiter.wait(timeoutCtx, []common.Hash{common.HexToHash(hash)}, confirmations)
+	if err != nil {
+		return nil, timeoutErr(ctx, err)
+	}
+	tm.replacements.remove(receipt.TxHash)
+	tm.followReorg(receipt.TxHash, confirmations)
+	return receipt, nil
+}
+
+// WaitReceipts waits receipts of hashes at the same time, each mined with confirmations blocks
+// receipts are in the order of hashes, the ones not confirmed before timeout are nil
+func (tm *TransactionManager) WaitReceipts(hashes []string, confirmations uint64) ([]*types.Receipt, error) {
+	return tm.WaitReceiptsContext(context.Background(), hashes, confirmations)
+}
+
+// WaitReceiptsContext is like WaitReceipts but with ctx for cancellation and deadline
+func (tm *TransactionManager) WaitReceiptsContext(ctx context.Context, hashes []string, confirmations uint64) ([]*types.Receipt, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(tm.timeout))
+	defer cancel()
+
+	receipts, err := tm.waiter.waitAll(timeoutCtx, hashes, confirmations)
+	for _, receipt := range receipts {
+		if receipt != nil {
+			tm.replacements.remove(receipt.TxHash)
+			tm.followReorg(receipt.TxHash, confirmations)
+		}
+	}
+	return receipts, timeoutErr(ctx, err)
+}
+
+// MinedReplacementSync waits until one of hash and its replacements is mined with tm.confirmations,
+// return hash and receipt of the mined one
+func (tm *TransactionManager) MinedReplacementSync(hash string) (string, *types.Receipt, error) {
+	return tm.MinedReplacementSyncContext(context.Background(), hash)
+}
+
+// MinedReplacementSyncContext is like MinedReplacementSync but with ctx for cancellation and deadline
+func (tm *TransactionManager) MinedReplacementSyncContext(ctx context.Context, hash string) (string, *types.Receipt, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(tm.timeout))
+	defer cancel()
+
+	receipt, err := tm.waiter.wait(timeoutCtx, tm.replacements.get(common.HexToHash(hash)), tm.confirmations)
+	if err != nil {
+		return "", nil, timeoutErr(ctx, err)
+	}
+	tm.replacements.remove(receipt.TxHash)
+	tm.followReorg(receipt.TxHash, tm.confirmations)
+	return receipt.TxHash.String(), receipt, nil
+}
@@ -0,0 +1,374 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// fakeChain mines transactions into blocks, it supports new head subscription when feed is not nil
+type fakeChain struct {
+	mu       sync.Mutex
+	head     uint64
+	fork     uint64 // changes block hashes after reorg
+	mined    map[common.Hash]uint64
+	feed     *event.Feed
+	errs     []error // returned by TransactionReceipt in turn
+	headErrs []error // returned by BlockNumber in turn
+}
+
+func newFakeChain(subscription bool) *fakeChain {
+	c := &fakeChain{mined: make(map[common.Hash]uint64)}
+	if subscription {
+		c.feed = new(event.Feed)
+	}
+	return c
+}
+
+func (c *fakeChain) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	if c.feed == nil {
+		return nil, ethereum.NotFound
+	}
+	return c.feed.Subscribe(ch), nil
+}
+
+func (c *fakeChain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.headErrs) > 0 {
+		err := c.headErrs[0]
+		c.headErrs = c.headErrs[1:]
+		return 0, err
+	}
+	return c.head, nil
+}
+
+func (c *fakeChain) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return nil, err
+	}
+	number, ok := c.mined[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return &types.Receipt{
+		TxHash:      hash,
+		BlockNumber: new(big.Int).SetUint64(number),
+		BlockHash:   common.BigToHash(new(big.Int).SetUint64(number + c.fork<<32)),
+		Status:      types.ReceiptStatusSuccessful,
+	}, nil
+}
+
+// reorg replaces the head block with an empty block of a new fork
+func (c *fakeChain) reorg() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fork++
+	for h, number := range c.mined {
+		if number == c.head {
+			delete(c.mined, h)
+		}
+	}
+}
+
+// mine appends a block including hashes
+func (c *fakeChain) mine(hashes ...common.Hash) {
+	c.mu.Lock()
+	c.head++
+	for _, h := range hashes {
+		c.mined[h] = c.head
+	}
+	head := &types.Header{Number: new(big.Int).SetUint64(c.head)}
+	c.mu.Unlock()
+	if c.feed != nil {
+		c.feed.Send(head)
+	}
+}
+
+func testWaiterConfirmations(t *testing.T, subscription bool) {
+	chain := newFakeChain(subscription)
+	w := newReceiptWaiter(chain, 10*time.Millisecond)
+	hash := common.HexToHash("0x01")
+
+	done := make(chan *types.Receipt, 1)
+	go func() {
+		receipt, err := w.wait(context.Background(), []common.Hash{hash}, 3)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- receipt
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	chain.mine(hash)
+	time.Sleep(30 * time.Millisecond)
+	chain.mine()
+	select {
+	case <-done:
+		t.Fatal("receipt returned before 3 confirmations")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	chain.mine()
+	select {
+	case receipt := <-done:
+		if receipt.TxHash != hash || receipt.BlockNumber.Uint64() != 1 {
+			t.Fatalf("unexpected receipt: %+v", receipt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("receipt not returned after 3 confirmations")
+	}
+}
+
+func TestReceiptWaiterPolling(t *testing.T) {
+	testWaiterConfirmations(t, false)
+}
+
+func TestReceiptWaiterSubscription(t *testing.T) {
+	testWaiterConfirmations(t, true)
+}
+
+func TestReceiptWaiterMany(t *testing.T) {
+	chain := newFakeChain(false)
+	w := newReceiptWaiter(chain, 10*time.Millisecond)
+
+	var hashes []string
+	var all []common.Hash
+	for i := 1; i <= 50; i++ {
+		h := common.BigToHash(big.NewInt(int64(i)))
+		hashes = append(hashes, h.String())
+		all = append(all, h)
+	}
+	chain.mine(all...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	receipts, err := w.waitAll(ctx, hashes, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range receipts {
+		if r == nil || r.TxHash != all[i] {
+			t.Fatalf("receipt %v: %+v", i, r)
+		}
+	}
+}
+
+func TestReceiptWaiterCancel(t *testing.T) {
+	chain := newFakeChain(false)
+	w := newReceiptWaiter(chain, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := w.wait(ctx, []common.Hash{common.HexToHash("0x01")}, 1); err != context.DeadlineExceeded {
+		t.Fatalf("expect deadline exceeded, got %v", err)
+	}
+
+	// loop exits when nothing is waited
+	time.Sleep(50 * time.Millisecond)
+	w.mu.Lock()
+	running := w.running
+	w.mu.Unlock()
+	if running {
+		t.Fatal("waiter loop still running")
+	}
+}
+
+func TestReceiptWaiterReorg(t *testing.T) {
+	chain := newFakeChain(false)
+	w := newReceiptWaiter(chain, 10*time.Millisecond)
+	rebroadcast := make(chan common.Hash, 1)
+	w.rebroadcast = func(ctx context.Context, hash common.Hash) {
+		rebroadcast <- hash
+	}
+	events := make(chan TxEvent, 16)
+	sub := w.subscribe(events)
+	defer sub.Unsubscribe()
+
+	hash := common.HexToHash("0x01")
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.wait(context.Background(), []common.Hash{hash}, 3)
+		done <- err
+	}()
+
+	expect := func(typ TxEventType) {
+		select {
+		case ev := <-events:
+			if ev.Type != typ || ev.Hash != hash {
+				t.Fatalf("expect %v event, got %v of %v", typ, ev.Type, ev.Hash.String())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %v event", typ)
+		}
+	}
+
+	chain.mine(hash)
+	expect(TxMined)
+	chain.reorg()
+	expect(TxDropped)
+	select {
+	case h := <-rebroadcast:
+		if h != hash {
+			t.Fatalf("re-broadcast %v", h.String())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("dropped tx is not re-broadcast")
+	}
+	chain.mine(hash)
+	expect(TxReincluded)
+	chain.mine()
+	chain.mine()
+	expect(TxConfirmed)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReceiptWaiterRetry(t *testing.T) {
+	chain := newFakeChain(false)
+	w := newReceiptWaiter(chain, 10*time.Millisecond)
+	w.retry = fastRetry
+
+	hash := common.HexToHash("0x01")
+	chain.mine(hash)
+	chain.errs = []error{errors.New("header not found"), errors.New("502 Bad Gateway")}
+	receipt, err := w.wait(context.Background(), []common.Hash{hash}, 1)
+	if err != nil || receipt.TxHash != hash {
+		t.Fatalf("transient errors: %v %v", receipt, err)
+	}
+
+	// terminal error fails the wait instead of being swallowed
+	chain.mu.Lock()
+	chain.errs = []error{errors.New("unauthorized")}
+	chain.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := w.wait(ctx, []common.Hash{common.HexToHash("0x02")}, 1); err == nil || err == context.DeadlineExceeded {
+		t.Fatalf("expect error of receipt, got %v", err)
+	}
+
+	// failure of block number doesn't end waits, they go on until their deadline
+	hash = common.HexToHash("0x03")
+	chain.mu.Lock()
+	chain.headErrs = []error{errors.New("unauthorized")}
+	chain.mu.Unlock()
+	chain.mine(hash)
+	if receipt, err := w.wait(ctx, []common.Hash{hash}, 1); err != nil || receipt.TxHash != hash {
+		t.Fatalf("wait after BlockNumber error: %v %v", receipt, err)
+	}
+	chain.mu.Lock()
+	left := len(chain.headErrs)
+	chain.mu.Unlock()
+	if left != 0 {
+		t.Fatal("BlockNumber error is not returned")
+	}
+}
+
+func TestReceiptWaiterSlowSubscriber(t *testing.T) {
+	chain := newFakeChain(false)
+	w := newReceiptWaiter(chain, 10*time.Millisecond)
+	// nobody reads events
+	sub := w.subscribe(make(chan TxEvent))
+	defer sub.Unsubscribe()
+
+	var hashes []string
+	var all []common.Hash
+	for i := 1; i <= txEventBuffer; i++ {
+		h := common.BigToHash(big.NewInt(int64(i)))
+		hashes = append(hashes, h.String())
+		all = append(all, h)
+	}
+	chain.mine(all...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := w.waitAll(ctx, hashes, 1); err != nil {
+		t.Fatalf("waits are blocked by subscriber: %v", err)
+	}
+
+	// dropped events are counted instead of logged one by one
+	w.subsMu.Lock()
+	dropped := sub.(*txEventSub).dropped
+	w.subsMu.Unlock()
+	if dropped == 0 {
+		t.Fatal("no event is dropped")
+	}
+
+	sub.Unsubscribe()
+	if _, ok := <-sub.Err(); ok {
+		t.Fatal("Err() is not closed after Unsubscribe")
+	}
+	w.subsMu.Lock()
+	subs := len(w.subs)
+	w.subsMu.Unlock()
+	if subs != 0 {
+		t.Fatalf("%v subscribers after Unsubscribe", subs)
+	}
+}
+
+func TestReorgDepth(t *testing.T) {
+	chain := newFakeChain(false)
+	tm := &TransactionManager{timeout: 5, reorgDepth: 3, waiter: newReceiptWaiter(chain, 10*time.Millisecond)}
+	rebroadcast := make(chan common.Hash, 1)
+	tm.waiter.rebroadcast = func(ctx context.Context, hash common.Hash) {
+		rebroadcast <- hash
+	}
+	events := make(chan TxEvent, 16)
+	sub := tm.SubscribeTxEvents(events)
+	defer sub.Unsubscribe()
+
+	hash := common.HexToHash("0x01")
+	chain.mine(hash)
+	if _, err := tm.WaitReceipt(hash.String(), 1); err != nil {
+		t.Fatal(err)
+	}
+	expect := func(typ TxEventType) {
+		select {
+		case ev := <-events:
+			if ev.Type != typ || ev.Hash != hash {
+				t.Fatalf("expect %v event, got %v of %v", typ, ev.Type, ev.Hash.String())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %v event", typ)
+		}
+	}
+	expect(TxMined)
+	expect(TxConfirmed)
+
+	// returned tx is still tracked
+	chain.reorg()
+	expect(TxDropped)
+	select {
+	case <-rebroadcast:
+	case <-time.After(time.Second):
+		t.Fatal("dropped tx is not re-broadcast")
+	}
+	chain.mine(hash)
+	expect(TxReincluded)
+
+	// tracking ends at the reorg depth without another confirmed event
+	chain.mine()
+	chain.mine()
+	time.Sleep(50 * time.Millisecond)
+	if waits := tm.waiter.pending(); waits != nil {
+		t.Fatalf("%v waits after reorg depth", len(waits))
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected %v event", ev.Type)
+	default:
+	}
+}